@@ -0,0 +1,134 @@
+// Package pubsub is a tiny in-process, revision-ordered event bus shared by
+// discovery-service and configuration-service. Both services need the same
+// shape of "watch since revision X" semantics (service registrations,
+// config key changes) so the bookkeeping — monotonic revisions, buffered
+// history for resume-after-disconnect, fan-out to live subscribers — lives
+// here instead of being duplicated per service.
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Event is one change published to a Topic. Revision is monotonically
+// increasing per Topic and never reused, so a client can resume a watch
+// from the last revision it saw.
+type Event struct {
+	Revision uint64
+	Type     string // e.g. "put", "delete"
+	Key      string // e.g. service name, or config key
+	Value    interface{}
+}
+
+// Topic fans a sequence of Events out to any number of subscribers and
+// keeps a bounded backlog so a subscriber that reconnects with a known
+// revision can replay what it missed instead of re-listing everything.
+type Topic struct {
+	mu         sync.Mutex
+	revision   uint64
+	backlog    []Event
+	backlogCap int
+	subs       map[chan Event]struct{}
+}
+
+// NewTopic creates a Topic that retains up to backlogCap historical events
+// for resume support. A backlogCap of 0 disables resume: subscribers only
+// see events published after they subscribe.
+func NewTopic(backlogCap int) *Topic {
+	return &Topic{backlogCap: backlogCap, subs: make(map[chan Event]struct{})}
+}
+
+// Publish appends an event, assigning it the next revision, and delivers it
+// to every current subscriber. Slow subscribers are dropped rather than
+// blocking the publisher; they must resubscribe with Subscribe's fromRevision.
+func (t *Topic) Publish(eventType, key string, value interface{}) Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.revision++
+	event := Event{Revision: t.revision, Type: eventType, Key: key, Value: value}
+
+	if t.backlogCap > 0 {
+		t.backlog = append(t.backlog, event)
+		if len(t.backlog) > t.backlogCap {
+			t.backlog = t.backlog[len(t.backlog)-t.backlogCap:]
+		}
+	}
+
+	for ch := range t.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(t.subs, ch)
+			close(ch)
+		}
+	}
+	return event
+}
+
+// Revision returns the current (last-assigned) revision.
+func (t *Topic) Revision() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.revision
+}
+
+// Subscribe returns a channel of Events starting from fromRevision+1. If
+// fromRevision is within the retained backlog, those events are replayed
+// before live events. If fromRevision is older than the retained backlog
+// (or backlog is disabled), replay starts from the oldest retained event;
+// callers that need a gap-free resume should re-list the full state in
+// that case.
+//
+// The returned cancel func must be called to release the subscription.
+func (t *Topic) Subscribe(ctx context.Context, fromRevision uint64) (<-chan Event, func()) {
+	t.mu.Lock()
+	ch := make(chan Event, 64)
+	t.subs[ch] = struct{}{}
+
+	var replay []Event
+	for _, event := range t.backlog {
+		if event.Revision > fromRevision {
+			replay = append(replay, event)
+		}
+	}
+	t.mu.Unlock()
+
+	out := make(chan Event, 64)
+	go func() {
+		defer close(out)
+		for _, event := range replay {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		for {
+			select {
+			case event, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	cancel := func() {
+		t.mu.Lock()
+		if _, ok := t.subs[ch]; ok {
+			delete(t.subs, ch)
+			close(ch)
+		}
+		t.mu.Unlock()
+	}
+	return out, cancel
+}
@@ -0,0 +1,129 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPublishAssignsMonotonicRevisions(t *testing.T) {
+	topic := NewTopic(16)
+
+	first := topic.Publish("put", "a", 1)
+	second := topic.Publish("put", "b", 2)
+
+	if first.Revision != 1 || second.Revision != 2 {
+		t.Fatalf("got revisions %d, %d; want 1, 2", first.Revision, second.Revision)
+	}
+	if topic.Revision() != 2 {
+		t.Fatalf("Revision() = %d, want 2", topic.Revision())
+	}
+}
+
+func TestSubscribeReplaysBacklogFromRevision(t *testing.T) {
+	topic := NewTopic(16)
+	topic.Publish("put", "a", 1)
+	topic.Publish("put", "b", 2)
+	topic.Publish("put", "c", 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, unsubscribe := topic.Subscribe(ctx, 1)
+	defer unsubscribe()
+
+	want := []string{"b", "c"}
+	for _, key := range want {
+		select {
+		case event := <-events:
+			if event.Key != key {
+				t.Fatalf("got key %q, want %q", event.Key, key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for replay of %q", key)
+		}
+	}
+}
+
+func TestSubscribeFromRevisionZeroReplaysEverything(t *testing.T) {
+	topic := NewTopic(16)
+	topic.Publish("put", "a", 1)
+	topic.Publish("put", "b", 2)
+
+	events, unsubscribe := topic.Subscribe(context.Background(), 0)
+	defer unsubscribe()
+
+	for _, key := range []string{"a", "b"} {
+		select {
+		case event := <-events:
+			if event.Key != key {
+				t.Fatalf("got key %q, want %q", event.Key, key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for replay of %q", key)
+		}
+	}
+}
+
+func TestSubscribeDeliversLiveEventsAfterReplay(t *testing.T) {
+	topic := NewTopic(16)
+	topic.Publish("put", "a", 1)
+
+	events, unsubscribe := topic.Subscribe(context.Background(), 0)
+	defer unsubscribe()
+
+	select {
+	case event := <-events:
+		if event.Key != "a" {
+			t.Fatalf("got key %q, want %q", event.Key, "a")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for backlog replay")
+	}
+
+	topic.Publish("put", "b", 2)
+	select {
+	case event := <-events:
+		if event.Key != "b" {
+			t.Fatalf("got key %q, want %q", event.Key, "b")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for live event")
+	}
+}
+
+func TestBacklogCapBoundsRetention(t *testing.T) {
+	topic := NewTopic(2)
+	topic.Publish("put", "a", 1)
+	topic.Publish("put", "b", 2)
+	topic.Publish("put", "c", 3)
+
+	events, unsubscribe := topic.Subscribe(context.Background(), 0)
+	defer unsubscribe()
+
+	// Only the last 2 events should have been retained.
+	for _, key := range []string{"b", "c"} {
+		select {
+		case event := <-events:
+			if event.Key != key {
+				t.Fatalf("got key %q, want %q", event.Key, key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %q", key)
+		}
+	}
+}
+
+func TestCancelClosesSubscription(t *testing.T) {
+	topic := NewTopic(16)
+	events, unsubscribe := topic.Subscribe(context.Background(), 0)
+	unsubscribe()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected channel to be closed after unsubscribe")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for channel to close")
+	}
+}
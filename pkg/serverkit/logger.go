@@ -0,0 +1,36 @@
+// Package serverkit provides the runtime scaffolding shared by
+// discovery-service and configuration-service: structured logging,
+// OpenTelemetry tracing, and graceful HTTP shutdown. Both services wire it
+// up the same way from main() so operational behavior doesn't drift between
+// them.
+package serverkit
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLogger builds the zap.Logger a service uses for structured, leveled
+// logging for the remainder of its process lifetime. LOG_LEVEL (debug,
+// info, warn, error) controls verbosity and defaults to info.
+func NewLogger(service string) (*zap.Logger, error) {
+	level := zapcore.InfoLevel
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(level)
+	cfg.EncoderConfig.TimeKey = "ts"
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	logger, err := cfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	return logger.With(zap.String("service", service)), nil
+}
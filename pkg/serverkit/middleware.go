@@ -0,0 +1,73 @@
+package serverkit
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the per-request ID on the response, generated
+// server-side unless the caller already supplied one.
+const RequestIDHeader = "X-Request-Id"
+
+// TraceIDHeader carries the active span's trace ID on the response so a
+// caller can correlate a response with the service's logs and traces.
+const TraceIDHeader = "X-Trace-Id"
+
+// Tracing starts a server span for each request, named after its matched
+// route, and attaches it to the request context so downstream handlers,
+// registry operations, and storage I/O share a single trace. It must run
+// before RequestLogger so the request ID and trace ID are available to log.
+func Tracing(service string) gin.HandlerFunc {
+	tracer := Tracer(service)
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		ctx, span := tracer.Start(c.Request.Context(), route)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequestLogger logs each request's method, path, status and latency as
+// structured fields, tagged with a request ID (forwarded via
+// X-Request-Id or generated) and, when Tracing ran first, the active
+// trace ID. Both IDs are echoed back on the response.
+func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		reqID := c.GetHeader(RequestIDHeader)
+		if reqID == "" {
+			reqID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, reqID)
+
+		traceID := trace.SpanContextFromContext(c.Request.Context()).TraceID()
+		if traceID.IsValid() {
+			c.Header(TraceIDHeader, traceID.String())
+		}
+
+		c.Next()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("remote", c.ClientIP()),
+			zap.String("request_id", reqID),
+		}
+		if traceID.IsValid() {
+			fields = append(fields, zap.String("trace_id", traceID.String()))
+		}
+		logger.Info("request", fields...)
+	}
+}
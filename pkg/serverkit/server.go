@@ -0,0 +1,62 @@
+package serverkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ShutdownGrace bounds how long Run waits for in-flight requests to finish,
+// and for cleanup funcs to return, once a shutdown signal arrives.
+const ShutdownGrace = 10 * time.Second
+
+// Run starts handler on addr and blocks until the process receives
+// SIGINT/SIGTERM. On signal it gracefully drains in-flight requests via
+// http.Server.Shutdown, then runs cleanup funcs in order so callers can stop
+// background tickers and flush storage before the process exits. Run
+// returns any error from serving or from the initial listen; shutdown-path
+// errors are logged rather than returned, since the process is exiting
+// either way.
+func Run(logger *zap.Logger, addr string, handler http.Handler, cleanup ...func(context.Context) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: addr, Handler: handler}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("listening", zap.String("addr", addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+			return
+		}
+		serveErr <- nil
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutdown signal received, draining in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownGrace)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("http server shutdown", zap.Error(err))
+	}
+
+	for _, fn := range cleanup {
+		if err := fn(shutdownCtx); err != nil {
+			logger.Warn("cleanup", zap.Error(err))
+		}
+	}
+
+	return <-serveErr
+}
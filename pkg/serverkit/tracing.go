@@ -0,0 +1,69 @@
+package serverkit
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InitTracing installs the global OpenTelemetry TracerProvider for service.
+// OTEL_EXPORTER selects where spans go:
+//   - "otlp": OTLP/gRPC, endpoint from OTEL_EXPORTER_OTLP_ENDPOINT
+//     (default "localhost:4317")
+//   - "stdout": pretty-printed spans on stdout, for local development
+//   - "" or "none": spans are recorded (so trace IDs still appear in logs
+//     and response headers) but never exported
+//
+// The returned shutdown func flushes and closes the exporter; callers
+// should defer it from main.
+func InitTracing(ctx context.Context, service string) (func(context.Context) error, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch kind := os.Getenv("OTEL_EXPORTER"); kind {
+	case "otlp":
+		endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if endpoint == "" {
+			endpoint = "localhost:4317"
+		}
+		exporter, err = otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "", "none":
+		exporter = nil
+	default:
+		return nil, fmt.Errorf("serverkit: unknown OTEL_EXPORTER %q", kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("serverkit: creating trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(service)))
+	if err != nil {
+		return nil, fmt.Errorf("serverkit: building trace resource: %w", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Tracer returns a named tracer from the global TracerProvider, for use by
+// handlers, registry operations, and storage I/O that want their own spans.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
@@ -1,41 +1,87 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"net"
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/van1sh/Mirage/pkg/serverkit"
 	"github.com/van1sh/Mirage/services/configuration-service/internal/api"
+	"github.com/van1sh/Mirage/services/configuration-service/internal/api/configurationpb"
 	"github.com/van1sh/Mirage/services/configuration-service/internal/config"
 	"github.com/van1sh/Mirage/services/configuration-service/internal/storage"
 )
 
+const serviceName = "configuration-service"
+
 func main() {
-	// Initialize logger
-	log.Println("Starting configuration service...")
+	logger, err := serverkit.NewLogger(serviceName)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize logger: %v", err))
+	}
+	defer logger.Sync() //nolint:errcheck
+
+	logger.Info("starting configuration service")
+
+	shutdownTracing, err := serverkit.InitTracing(context.Background(), serviceName)
+	if err != nil {
+		logger.Fatal("failed to initialize tracing", zap.Error(err))
+	}
 
 	// Load application config
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logger.Fatal("failed to load configuration", zap.Error(err))
 	}
 
 	// Initialize storage
 	store, err := storage.NewFileStorage(cfg.StoragePath)
 	if err != nil {
-		log.Fatalf("Failed to initialize storage: %v", err)
+		logger.Fatal("failed to initialize storage", zap.Error(err))
+	}
+
+	// Initialize the key-encryption key provider used for secret values.
+	kek, err := config.BuildKEKProvider(context.Background(), cfg)
+	if err != nil {
+		logger.Fatal("failed to initialize KEK provider", zap.Error(err))
 	}
+	store.SetKEKProvider(kek)
 
 	// Setup Gin router
-	router := gin.Default()
+	router := gin.New()
 
 	// Register middleware
 	router.Use(gin.Recovery())
 	router.Use(api.CORSMiddleware())
-	router.Use(api.RequestLogger())
+	router.Use(serverkit.Tracing(serviceName))
+	router.Use(serverkit.RequestLogger(logger))
 
 	// Register routes
-	api.RegisterRoutes(router, store)
+	api.RegisterRoutes(router, store, cfg, logger)
+
+	// Start the gRPC watch server alongside the HTTP API so clients can
+	// subscribe to config changes over either transport.
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9092"
+	}
+	grpcServer := grpc.NewServer()
+	go func() {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			logger.Fatal("failed to listen for gRPC", zap.String("port", grpcPort), zap.Error(err))
+		}
+		configurationpb.RegisterConfigWatchServer(grpcServer, api.NewWatchServer(store))
+		logger.Info("configuration service gRPC watch listening", zap.String("port", grpcPort))
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.Fatal("gRPC server failed", zap.Error(err))
+		}
+	}()
 
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
@@ -43,9 +89,19 @@ func main() {
 		port = "8092"
 	}
 
-	// Start the server
-	log.Printf("Configuration service listening on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	err = serverkit.Run(logger, ":"+port, router,
+		func(ctx context.Context) error {
+			grpcServer.GracefulStop()
+			return nil
+		},
+		func(ctx context.Context) error {
+			return store.Close()
+		},
+		func(ctx context.Context) error {
+			return shutdownTracing(ctx)
+		},
+	)
+	if err != nil {
+		logger.Fatal("configuration service exited with error", zap.Error(err))
 	}
 }
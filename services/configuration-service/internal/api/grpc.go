@@ -0,0 +1,46 @@
+package api
+
+import (
+	"github.com/van1sh/Mirage/services/configuration-service/internal/api/configurationpb"
+	"github.com/van1sh/Mirage/services/configuration-service/internal/storage"
+)
+
+// watchServer implements configurationpb.ConfigWatchServer, the gRPC
+// counterpart to the SSE endpoints registered in routes.go. Both read from
+// the same storage.FileStorage watch feed.
+type watchServer struct {
+	configurationpb.UnimplementedConfigWatchServer
+	store *storage.FileStorage
+}
+
+// NewWatchServer returns a gRPC ConfigWatchServer backed by store.
+func NewWatchServer(store *storage.FileStorage) configurationpb.ConfigWatchServer {
+	return &watchServer{store: store}
+}
+
+func (s *watchServer) Watch(req *configurationpb.WatchRequest, stream configurationpb.ConfigWatch_WatchServer) error {
+	events, cancel := s.store.Watch(stream.Context(), req.GetPrefix(), req.GetFromRevision())
+	defer cancel()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			out := &configurationpb.WatchEvent{
+				Revision: event.Revision,
+				Type:     event.Type,
+				Key:      event.Key,
+			}
+			if value, ok := event.Value.(string); ok {
+				out.Value = value
+			}
+			if err := stream.Send(out); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
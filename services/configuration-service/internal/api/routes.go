@@ -0,0 +1,442 @@
+// Package api wires the configuration-service HTTP surface: CRUD over
+// config keys (with revision history, rollback and diff), namespace/schema
+// administration, plus a watch API backed by internal/storage's revisioned
+// change feed.
+package api
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/van1sh/Mirage/services/configuration-service/internal/config"
+	"github.com/van1sh/Mirage/services/configuration-service/internal/storage"
+)
+
+// CORSMiddleware allows the service to be queried from browser-based
+// dashboards without a proxy.
+func CORSMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RegisterRoutes mounts the configuration-service API onto router.
+// cfg.RevealToken gates GET /configs/:key/reveal and POST /admin/rotate-kek;
+// an empty token disables both routes entirely rather than accepting
+// unauthenticated requests.
+func RegisterRoutes(router *gin.Engine, store *storage.FileStorage, cfg *config.Config, logger *zap.Logger) {
+	router.GET("/configs", listHandler(store))
+	router.GET("/configs/:key", getHandler(store))
+	router.PUT("/configs/:key", setHandler(store))
+	router.DELETE("/configs/:key", deleteHandler(store))
+	router.GET("/configs/:key/history", historyHandler(store))
+	router.GET("/configs/:key/diff", diffHandler(store))
+	router.POST("/configs/:key/rollback/:rev", rollbackHandler(store))
+	router.GET("/configs/:key/watch", watchKeyHandler(store))
+	router.GET("/watch", watchAllHandler(store))
+
+	router.GET("/configs/:key/reveal", revealAuth(cfg.RevealToken), revealHandler(store, logger))
+	router.POST("/admin/rotate-kek", revealAuth(cfg.RevealToken), rotateKEKHandler(store, cfg, logger))
+
+	router.PUT("/namespaces/:namespace", setNamespaceHandler(store))
+	router.PUT("/namespaces/:namespace/schema", setSchemaHandler(store))
+}
+
+// namespaceOf resolves the ?ns= query param, defaulting to
+// storage.DefaultNamespace so existing untagged clients keep working.
+func namespaceOf(c *gin.Context) string {
+	ns := c.Query("ns")
+	if ns == "" {
+		return storage.DefaultNamespace
+	}
+	return ns
+}
+
+func getHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ns, key := namespaceOf(c), c.Param("key")
+
+		var (
+			value string
+			ok    bool
+			err   error
+		)
+		switch {
+		case c.Query("revision") != "":
+			rev, perr := strconv.ParseUint(c.Query("revision"), 10, 64)
+			if perr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+				return
+			}
+			value, ok, err = store.GetAtRevision(ns, key, rev)
+		case c.Query("at") != "":
+			at, perr := time.Parse(time.RFC3339, c.Query("at"))
+			if perr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid at, expected RFC3339 timestamp"})
+				return
+			}
+			value, ok, err = store.GetAtTime(ns, key, at)
+		default:
+			value, ok, err = store.Get(c.Request.Context(), ns, key)
+		}
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"namespace": ns, "key": key, "value": value})
+	}
+}
+
+// revealAuth requires a `Bearer <token>` Authorization header matching
+// revealToken before letting a request reach the reveal handler. An empty
+// revealToken closes the route rather than allowing anonymous access.
+func revealAuth(revealToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if revealToken == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "reveal endpoint is disabled (MIRAGE_REVEAL_TOKEN not set)"})
+			return
+		}
+		got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if got == "" || subtle.ConstantTimeCompare([]byte(got), []byte(revealToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid reveal token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// revealHandler decrypts and returns a secret's current plaintext value.
+// Every call is audit-logged regardless of outcome.
+func revealHandler(store *storage.FileStorage, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ns, key := namespaceOf(c), c.Param("key")
+		value, ok, err := store.Reveal(c.Request.Context(), ns, key)
+
+		outcome := "ok"
+		switch {
+		case err != nil:
+			outcome = "error: " + err.Error()
+		case !ok:
+			outcome = "not_found"
+		}
+		logger.Info("AUDIT reveal",
+			zap.String("namespace", ns),
+			zap.String("key", key),
+			zap.String("remote", c.ClientIP()),
+			zap.String("outcome", outcome),
+		)
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "key not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"namespace": ns, "key": key, "value": value})
+	}
+}
+
+// rotateKEKRequest overrides the subset of cfg's KEK settings relevant to
+// the new provider; fields left empty fall back to the process's current
+// configuration, so e.g. rotating to a new Vault Transit key only needs
+// provider ("vault") and vault_transit_key set. Provider is always required.
+type rotateKEKRequest struct {
+	Provider        string `json:"provider"`
+	LocalPath       string `json:"local_path,omitempty"`
+	VaultAddr       string `json:"vault_addr,omitempty"`
+	VaultToken      string `json:"vault_token,omitempty"`
+	VaultTransitKey string `json:"vault_transit_key,omitempty"`
+	AWSKMSKeyID     string `json:"aws_kms_key_id,omitempty"`
+	GCPKMSKeyName   string `json:"gcp_kms_key_name,omitempty"`
+}
+
+// rotateKEKHandler re-wraps every secret's DEK under a newly constructed
+// KEKProvider and makes it the active provider for future writes/reveals.
+// Every call is audit-logged regardless of outcome, same as revealHandler.
+func rotateKEKHandler(store *storage.FileStorage, cfg *config.Config, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req rotateKEKRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Provider == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "provider is required"})
+			return
+		}
+
+		newCfg := *cfg
+		newCfg.KEKProvider = req.Provider
+		if req.LocalPath != "" {
+			newCfg.LocalKEKPath = req.LocalPath
+		}
+		if req.VaultAddr != "" {
+			newCfg.VaultAddr = req.VaultAddr
+		}
+		if req.VaultToken != "" {
+			newCfg.VaultToken = req.VaultToken
+		}
+		if req.VaultTransitKey != "" {
+			newCfg.VaultTransitKey = req.VaultTransitKey
+		}
+		if req.AWSKMSKeyID != "" {
+			newCfg.AWSKMSKeyID = req.AWSKMSKeyID
+		}
+		if req.GCPKMSKeyName != "" {
+			newCfg.GCPKMSKeyName = req.GCPKMSKeyName
+		}
+
+		newKEK, err := config.BuildKEKProvider(c.Request.Context(), &newCfg)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rewrapped, err := store.RotateKEK(c.Request.Context(), newKEK)
+
+		outcome := "ok"
+		if err != nil {
+			outcome = "error: " + err.Error()
+		}
+		logger.Info("AUDIT rotate-kek",
+			zap.String("provider", req.Provider),
+			zap.String("remote", c.ClientIP()),
+			zap.Int("rewrapped", rewrapped),
+			zap.String("outcome", outcome),
+		)
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"provider": req.Provider, "rewrapped": rewrapped})
+	}
+}
+
+type setRequest struct {
+	Value   string `json:"value"`
+	Secret  bool   `json:"secret"`
+	Author  string `json:"author"`
+	Message string `json:"message"`
+}
+
+func setHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req setRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ns, key := namespaceOf(c), c.Param("key")
+
+		var err error
+		if req.Secret {
+			err = store.SetSecret(c.Request.Context(), ns, key, req.Value, req.Author, req.Message)
+		} else {
+			err = store.Set(c.Request.Context(), ns, key, req.Value, req.Author, req.Message)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp := gin.H{"namespace": ns, "key": key, "revision": store.Revision()}
+		if req.Secret {
+			resp["value"] = storage.SecretMask
+		} else {
+			resp["value"] = req.Value
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+type deleteRequest struct {
+	Author  string `json:"author"`
+	Message string `json:"message"`
+}
+
+func deleteHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req deleteRequest
+		// Body is optional for deletes; ignore binding errors from an empty body.
+		_ = c.ShouldBindJSON(&req)
+		if err := store.Delete(c.Request.Context(), namespaceOf(c), c.Param("key"), req.Author, req.Message); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func listHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		values, err := store.List(c.Request.Context(), namespaceOf(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, values)
+	}
+}
+
+func historyHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		versions, err := store.History(namespaceOf(c), c.Param("key"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, versions)
+	}
+}
+
+func diffHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, err := strconv.ParseUint(c.Query("from"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or missing from"})
+			return
+		}
+		to, err := strconv.ParseUint(c.Query("to"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or missing to"})
+			return
+		}
+		diff, err := store.Diff(namespaceOf(c), c.Param("key"), from, to)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, diff)
+	}
+}
+
+type rollbackRequest struct {
+	Author  string `json:"author"`
+	Message string `json:"message"`
+}
+
+func rollbackHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rev, err := strconv.ParseUint(c.Param("rev"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+			return
+		}
+		var req rollbackRequest
+		_ = c.ShouldBindJSON(&req)
+
+		version, err := store.Rollback(c.Request.Context(), namespaceOf(c), c.Param("key"), rev, req.Author, req.Message)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, version)
+	}
+}
+
+type setNamespaceRequest struct {
+	Parent string `json:"parent"`
+}
+
+func setNamespaceHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req setNamespaceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := store.SetNamespaceParent(c.Param("namespace"), req.Parent); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func setSchemaHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schema, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := store.RegisterSchema(c.Param("namespace"), schema); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// parseFromRevision reads the `revision` query parameter clients use to
+// resume a watch after a disconnect.
+func parseFromRevision(c *gin.Context) uint64 {
+	v := c.Query("revision")
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func watchKeyHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		streamWatch(c, store, namespaceOf(c)+"/"+c.Param("key"))
+	}
+}
+
+func watchAllHandler(store *storage.FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		streamWatch(c, store, c.Query("prefix"))
+	}
+}
+
+// streamWatch serves a Server-Sent Events stream of storage.Watch events so
+// clients can react to config changes without polling. Each event's
+// revision lets the client resume via ?revision= after a disconnect.
+func streamWatch(c *gin.Context, store *storage.FileStorage, prefix string) {
+	events, cancel := store.Watch(c.Request.Context(), prefix, parseFromRevision(c))
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("change", event)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,107 @@
+// Package config loads configuration-service settings from the process
+// environment, mirroring discovery-service's config package.
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/van1sh/Mirage/services/configuration-service/internal/secrets"
+)
+
+// KEK provider names accepted by MIRAGE_KEK_PROVIDER.
+const (
+	KEKLocal  = "local"
+	KEKVault  = "vault"
+	KEKAWSKMS = "awskms"
+	KEKGCPKMS = "gcpkms"
+)
+
+// Config holds everything main.go needs to initialize storage.
+type Config struct {
+	// StoragePath is the file backing storage.FileStorage.
+	StoragePath string
+
+	// RevealToken gates GET /configs/:key/reveal; requests must present it
+	// as a Bearer token. Empty disables the reveal endpoint entirely.
+	RevealToken string
+
+	// KEKProvider selects the secrets.KEKProvider implementation. See the
+	// KEK* constants above.
+	KEKProvider string
+
+	// LocalKEKPath is used when KEKProvider is "local".
+	LocalKEKPath string
+
+	// VaultAddr / VaultToken / VaultTransitKey configure the Vault Transit
+	// provider.
+	VaultAddr       string
+	VaultToken      string
+	VaultTransitKey string
+
+	// AWSKMSKeyID configures the AWS KMS provider (CMK ARN or ID).
+	AWSKMSKeyID string
+
+	// GCPKMSKeyName configures the GCP KMS provider (full CryptoKey
+	// resource name).
+	GCPKMSKeyName string
+}
+
+// Load reads Config from the environment, defaulting to a local data file
+// and a local-file KEK so the service runs out of the box in development.
+func Load() (*Config, error) {
+	cfg := &Config{
+		StoragePath:     "data/configuration-store.json",
+		KEKProvider:     KEKLocal,
+		LocalKEKPath:    "data/configuration-kek.hex",
+		VaultAddr:       "http://127.0.0.1:8200",
+		VaultTransitKey: "mirage-configuration-service",
+	}
+	if v := os.Getenv("MIRAGE_STORAGE_PATH"); v != "" {
+		cfg.StoragePath = v
+	}
+	if v := os.Getenv("MIRAGE_REVEAL_TOKEN"); v != "" {
+		cfg.RevealToken = v
+	}
+	if v := os.Getenv("MIRAGE_KEK_PROVIDER"); v != "" {
+		cfg.KEKProvider = v
+	}
+	if v := os.Getenv("MIRAGE_LOCAL_KEK_PATH"); v != "" {
+		cfg.LocalKEKPath = v
+	}
+	if v := os.Getenv("MIRAGE_VAULT_ADDR"); v != "" {
+		cfg.VaultAddr = v
+	}
+	if v := os.Getenv("MIRAGE_VAULT_TOKEN"); v != "" {
+		cfg.VaultToken = v
+	}
+	if v := os.Getenv("MIRAGE_VAULT_TRANSIT_KEY"); v != "" {
+		cfg.VaultTransitKey = v
+	}
+	if v := os.Getenv("MIRAGE_AWS_KMS_KEY_ID"); v != "" {
+		cfg.AWSKMSKeyID = v
+	}
+	if v := os.Getenv("MIRAGE_GCP_KMS_KEY_NAME"); v != "" {
+		cfg.GCPKMSKeyName = v
+	}
+	return cfg, nil
+}
+
+// BuildKEKProvider constructs the secrets.KEKProvider selected by
+// cfg.KEKProvider. Shared by main.go (startup) and the configuration API's
+// /admin/rotate-kek handler (manual rotation onto a new provider/key).
+func BuildKEKProvider(ctx context.Context, cfg *Config) (secrets.KEKProvider, error) {
+	switch cfg.KEKProvider {
+	case KEKLocal:
+		return secrets.NewLocalFileKEK(cfg.LocalKEKPath)
+	case KEKVault:
+		return secrets.NewVaultTransitKEK(cfg.VaultAddr, cfg.VaultToken, cfg.VaultTransitKey)
+	case KEKAWSKMS:
+		return secrets.NewAWSKMSKEK(ctx, cfg.AWSKMSKeyID)
+	case KEKGCPKMS:
+		return secrets.NewGCPKMSKEK(ctx, cfg.GCPKMSKeyName)
+	default:
+		return nil, fmt.Errorf("unknown KEK provider %q", cfg.KEKProvider)
+	}
+}
@@ -0,0 +1,52 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// AWSKMSKEK wraps/unwraps DEKs using an AWS KMS customer master key via
+// its Encrypt/Decrypt APIs, rather than GenerateDataKey, since we already
+// generate the DEK locally in envelope.go and only need KMS to protect it.
+type AWSKMSKEK struct {
+	client *kms.Client
+	keyID  string
+}
+
+// NewAWSKMSKEK builds a KMS client for the given CMK ARN/ID, using the
+// default AWS credential chain.
+func NewAWSKMSKEK(ctx context.Context, keyID string) (*AWSKMSKEK, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: loading aws config: %w", err)
+	}
+	return &AWSKMSKEK{client: kms.NewFromConfig(cfg), keyID: keyID}, nil
+}
+
+func (k *AWSKMSKEK) Name() string { return "aws-kms:" + k.keyID }
+
+func (k *AWSKMSKEK) WrapKey(ctx context.Context, dek []byte) ([]byte, error) {
+	out, err := k.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(k.keyID),
+		Plaintext: dek,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("secrets: kms encrypt: %w", err)
+	}
+	return out.CiphertextBlob, nil
+}
+
+func (k *AWSKMSKEK) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	out, err := k.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(k.keyID),
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("secrets: kms decrypt: %w", err)
+	}
+	return out.Plaintext, nil
+}
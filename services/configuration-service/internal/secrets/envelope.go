@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+)
+
+// Envelope is the at-rest representation of one encrypted value: a DEK
+// wraps the plaintext via AES-256-GCM, and the DEK itself is wrapped by
+// whichever KEKProvider produced WrappedDEK. Provider records that
+// provider's Name; Open and Rewrap do not consult it, so the caller must
+// pass a provider that can unwrap WrappedDEK.
+type Envelope struct {
+	Provider   string `json:"provider"`
+	WrappedDEK []byte `json:"wrapped_dek"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal generates a fresh DEK, encrypts plaintext with it, and wraps the DEK
+// with kek.
+func Seal(ctx context.Context, kek KEKProvider, plaintext []byte) (*Envelope, error) {
+	dek := make([]byte, 32) // AES-256
+	if _, err := rand.Read(dek); err != nil {
+		return nil, fmt.Errorf("secrets: generating dek: %w", err)
+	}
+	defer zero(dek)
+
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: creating cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: creating gcm: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("secrets: generating nonce: %w", err)
+	}
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	wrapped, err := kek.WrapKey(ctx, dek)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: wrapping dek: %w", err)
+	}
+
+	return &Envelope{
+		Provider:   kek.Name(),
+		WrappedDEK: wrapped,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// Open decrypts env using kek, which must be able to unwrap env.WrappedDEK
+// (i.e. be the same provider recorded in env.Provider, or a provider that
+// still honors that key's wrapping, such as during a gradual rotation).
+func Open(ctx context.Context, kek KEKProvider, env *Envelope) ([]byte, error) {
+	dek, err := kek.UnwrapKey(ctx, env.WrappedDEK)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: unwrapping dek: %w", err)
+	}
+	defer zero(dek)
+
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: creating cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: creating gcm: %w", err)
+	}
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: decrypting value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Rewrap decrypts env's DEK with oldKEK and wraps it again with newKEK,
+// leaving the ciphertext itself untouched. Used by key rotation.
+func Rewrap(ctx context.Context, oldKEK, newKEK KEKProvider, env *Envelope) (*Envelope, error) {
+	dek, err := oldKEK.UnwrapKey(ctx, env.WrappedDEK)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: unwrapping dek for rotation: %w", err)
+	}
+	defer zero(dek)
+
+	wrapped, err := newKEK.WrapKey(ctx, dek)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: rewrapping dek: %w", err)
+	}
+	return &Envelope{
+		Provider:   newKEK.Name(),
+		WrappedDEK: wrapped,
+		Nonce:      env.Nonce,
+		Ciphertext: env.Ciphertext,
+	}, nil
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func newTestLocalKEK(t *testing.T) *LocalFileKEK {
+	t.Helper()
+	kek, err := NewLocalFileKEK(filepath.Join(t.TempDir(), "kek.hex"))
+	if err != nil {
+		t.Fatalf("NewLocalFileKEK: %v", err)
+	}
+	return kek
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	kek := newTestLocalKEK(t)
+	plaintext := []byte("super secret value")
+
+	env, err := Seal(context.Background(), kek, plaintext)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if env.Provider != kek.Name() {
+		t.Fatalf("Provider = %q, want %q", env.Provider, kek.Name())
+	}
+
+	got, err := Open(context.Background(), kek, env)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("Open = %q, want %q", got, plaintext)
+	}
+}
+
+func TestOpenFailsWithWrongKEK(t *testing.T) {
+	kek := newTestLocalKEK(t)
+	other := newTestLocalKEK(t)
+
+	env, err := Seal(context.Background(), kek, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	if _, err := Open(context.Background(), other, env); err == nil {
+		t.Fatal("expected Open with a different KEK to fail, got nil error")
+	}
+}
+
+func TestRewrapAllowsOpenWithNewKEKOnly(t *testing.T) {
+	oldKEK := newTestLocalKEK(t)
+	newKEK := newTestLocalKEK(t)
+	plaintext := []byte("rotate me")
+
+	env, err := Seal(context.Background(), oldKEK, plaintext)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	rewrapped, err := Rewrap(context.Background(), oldKEK, newKEK, env)
+	if err != nil {
+		t.Fatalf("Rewrap: %v", err)
+	}
+	if rewrapped.Provider != newKEK.Name() {
+		t.Fatalf("Provider = %q, want %q", rewrapped.Provider, newKEK.Name())
+	}
+	if !bytes.Equal(rewrapped.Ciphertext, env.Ciphertext) || !bytes.Equal(rewrapped.Nonce, env.Nonce) {
+		t.Fatal("Rewrap must leave ciphertext and nonce untouched")
+	}
+
+	if _, err := Open(context.Background(), oldKEK, rewrapped); err == nil {
+		t.Fatal("expected Open with the old KEK to fail after rewrap")
+	}
+
+	got, err := Open(context.Background(), newKEK, rewrapped)
+	if err != nil {
+		t.Fatalf("Open with new KEK: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("Open = %q, want %q", got, plaintext)
+	}
+}
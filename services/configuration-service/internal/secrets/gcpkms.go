@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	kmspb "cloud.google.com/go/kms/apiv1/kmspb"
+)
+
+// GCPKMSKEK wraps/unwraps DEKs using a Google Cloud KMS CryptoKey's
+// Encrypt/Decrypt RPCs.
+type GCPKMSKEK struct {
+	client  *kms.KeyManagementClient
+	keyName string // projects/*/locations/*/keyRings/*/cryptoKeys/*
+}
+
+// NewGCPKMSKEK builds a KMS client for the given CryptoKey resource name,
+// using application-default credentials.
+func NewGCPKMSKEK(ctx context.Context, keyName string) (*GCPKMSKEK, error) {
+	client, err := kms.NewKeyManagementClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: creating gcp kms client: %w", err)
+	}
+	return &GCPKMSKEK{client: client, keyName: keyName}, nil
+}
+
+func (k *GCPKMSKEK) Name() string { return "gcp-kms:" + k.keyName }
+
+func (k *GCPKMSKEK) WrapKey(ctx context.Context, dek []byte) ([]byte, error) {
+	resp, err := k.client.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:      k.keyName,
+		Plaintext: dek,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("secrets: gcp kms encrypt: %w", err)
+	}
+	return resp.Ciphertext, nil
+}
+
+func (k *GCPKMSKEK) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := k.client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:       k.keyName,
+		Ciphertext: wrapped,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("secrets: gcp kms decrypt: %w", err)
+	}
+	return resp.Plaintext, nil
+}
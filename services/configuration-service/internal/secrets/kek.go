@@ -0,0 +1,20 @@
+// Package secrets implements envelope encryption for configuration values
+// marked secret: true. Each value gets its own random data-encryption key
+// (DEK), which encrypts the value; the DEK itself is wrapped by a
+// pluggable key-encryption key (KEK) provider so the master key material
+// never touches disk unencrypted and can live in an external KMS.
+package secrets
+
+import "context"
+
+// KEKProvider wraps and unwraps data-encryption keys using a key-encryption
+// key it owns. Implementations range from a key file on local disk to
+// external services (Vault Transit, AWS KMS, GCP KMS) that never return
+// the KEK itself, only the result of wrapping/unwrapping.
+type KEKProvider interface {
+	// Name identifies the provider, e.g. for logging and the envelope's
+	// provider tag. Nothing routes UnwrapKey calls by this name.
+	Name() string
+	WrapKey(ctx context.Context, dek []byte) (wrapped []byte, err error)
+	UnwrapKey(ctx context.Context, wrapped []byte) (dek []byte, err error)
+}
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// LocalFileKEK reads a 32-byte AES-256 master key from a file on disk and
+// uses it directly to wrap/unwrap DEKs with AES-GCM. It's the lowest-ceremony
+// option, suitable for development or single-node deployments that don't
+// have Vault/KMS available; the key file itself is the single point of
+// trust and must be protected by filesystem permissions.
+type LocalFileKEK struct {
+	key []byte
+}
+
+// NewLocalFileKEK loads (or, if absent, generates and writes) the master
+// key at path.
+func NewLocalFileKEK(path string) (*LocalFileKEK, error) {
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("secrets: generating local kek: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(key)), 0o600); err != nil {
+			return nil, fmt.Errorf("secrets: writing local kek to %s: %w", path, err)
+		}
+		return &LocalFileKEK{key: key}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("secrets: reading local kek from %s: %w", path, err)
+	}
+	key, err := hex.DecodeString(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("secrets: decoding local kek from %s: %w", path, err)
+	}
+	return &LocalFileKEK{key: key}, nil
+}
+
+func (k *LocalFileKEK) Name() string { return "local" }
+
+func (k *LocalFileKEK) WrapKey(_ context.Context, dek []byte) ([]byte, error) {
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return append(nonce, gcm.Seal(nil, nonce, dek, nil)...), nil
+}
+
+func (k *LocalFileKEK) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := gcm.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, fmt.Errorf("secrets: wrapped key too short")
+	}
+	nonce, ciphertext := wrapped[:nonceSize], wrapped[nonceSize:]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
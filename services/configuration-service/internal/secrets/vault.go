@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// VaultTransitKEK wraps/unwraps DEKs using HashiCorp Vault's Transit
+// secrets engine, so the KEK never leaves Vault: WrapKey/UnwrapKey are
+// just encrypt/decrypt calls against the named transit key.
+type VaultTransitKEK struct {
+	client  *vault.Client
+	keyName string
+}
+
+// NewVaultTransitKEK builds a client against addr using token, operating on
+// the transit key keyName (created in Vault ahead of time).
+func NewVaultTransitKEK(addr, token, keyName string) (*VaultTransitKEK, error) {
+	cfg := vault.DefaultConfig()
+	cfg.Address = addr
+	client, err := vault.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: creating vault client: %w", err)
+	}
+	client.SetToken(token)
+	return &VaultTransitKEK{client: client, keyName: keyName}, nil
+}
+
+func (k *VaultTransitKEK) Name() string { return "vault-transit:" + k.keyName }
+
+func (k *VaultTransitKEK) WrapKey(ctx context.Context, dek []byte) ([]byte, error) {
+	secret, err := k.client.Logical().WriteWithContext(ctx, "transit/encrypt/"+k.keyName, map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(dek),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("secrets: vault transit encrypt: %w", err)
+	}
+	ciphertext, ok := secret.Data["ciphertext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("secrets: vault transit encrypt: missing ciphertext in response")
+	}
+	return []byte(ciphertext), nil
+}
+
+func (k *VaultTransitKEK) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	secret, err := k.client.Logical().WriteWithContext(ctx, "transit/decrypt/"+k.keyName, map[string]interface{}{
+		"ciphertext": string(wrapped),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("secrets: vault transit decrypt: %w", err)
+	}
+	plaintext, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("secrets: vault transit decrypt: missing plaintext in response")
+	}
+	return base64.StdEncoding.DecodeString(plaintext)
+}
@@ -0,0 +1,665 @@
+// Package storage persists configuration key/value pairs for
+// configuration-service. FileStorage is a namespaced, versioned key/value
+// store: every write creates an immutable Version carrying author/message
+// metadata, namespaces inherit from a parent namespace so a key resolves
+// through a lookup chain (e.g. prod -> staging -> dev), and each namespace
+// may register a JSON Schema that values must satisfy before they commit.
+// Every write is also assigned a monotonic revision and broadcast to
+// watchers via pkg/pubsub, eliminating the need for clients to poll.
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/van1sh/Mirage/pkg/pubsub"
+	"github.com/van1sh/Mirage/pkg/serverkit"
+	"github.com/van1sh/Mirage/services/configuration-service/internal/secrets"
+)
+
+// tracer instruments the store's read/write path so storage I/O shows up
+// as child spans of the HTTP span that triggered it.
+var tracer = serverkit.Tracer("configuration-service/storage")
+
+// DefaultNamespace is used when callers don't specify one.
+const DefaultNamespace = "default"
+
+// Event types published on the store's watch feed.
+const (
+	EventPut    = "put"
+	EventDelete = "delete"
+)
+
+// watchBacklog bounds how many historical events FileStorage retains for
+// resuming a watch after a client reconnects.
+const watchBacklog = 256
+
+// maxLookupDepth guards namespace inheritance chains against accidental
+// cycles (e.g. a misconfigured prod -> staging -> prod loop).
+const maxLookupDepth = 16
+
+// Version is one immutable revision of a key's value. Secret versions
+// carry an Envelope instead of a plaintext Value; Value stays empty for
+// them so the plaintext is never written to the store's JSON file.
+type Version struct {
+	Revision  uint64            `json:"revision"`
+	Value     string            `json:"value,omitempty"`
+	Secret    bool              `json:"secret,omitempty"`
+	Envelope  *secrets.Envelope `json:"envelope,omitempty"`
+	Author    string            `json:"author,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	Deleted   bool              `json:"deleted,omitempty"`
+}
+
+// keyHistory is every version ever written for one key, newest last.
+type keyHistory struct {
+	Versions []Version `json:"versions"`
+}
+
+func (h *keyHistory) current() (Version, bool) {
+	if len(h.Versions) == 0 {
+		return Version{}, false
+	}
+	last := h.Versions[len(h.Versions)-1]
+	return last, !last.Deleted
+}
+
+func (h *keyHistory) atRevision(revision uint64) (Version, bool) {
+	for i := len(h.Versions) - 1; i >= 0; i-- {
+		if h.Versions[i].Revision <= revision {
+			if h.Versions[i].Deleted {
+				return Version{}, false
+			}
+			return h.Versions[i], true
+		}
+	}
+	return Version{}, false
+}
+
+func (h *keyHistory) atTime(at time.Time) (Version, bool) {
+	var found Version
+	ok := false
+	for _, v := range h.Versions {
+		if v.Timestamp.After(at) {
+			break
+		}
+		found, ok = v, !v.Deleted
+	}
+	return found, ok
+}
+
+// namespace groups a set of key histories plus an optional parent to
+// inherit unset keys from, and an optional JSON Schema new values must
+// validate against.
+type namespace struct {
+	Parent string                 `json:"parent,omitempty"`
+	Schema json.RawMessage        `json:"schema,omitempty"`
+	Keys   map[string]*keyHistory `json:"keys"`
+
+	schema *gojsonschema.Schema
+}
+
+type fileContents struct {
+	Revision   uint64                `json:"revision"`
+	Namespaces map[string]*namespace `json:"namespaces"`
+}
+
+// FileStorage is a JSON-file-backed, namespaced, versioned key/value store.
+// It is safe for concurrent use.
+type FileStorage struct {
+	mu         sync.RWMutex
+	path       string
+	namespaces map[string]*namespace
+	revision   uint64
+	topic      *pubsub.Topic
+	kek        secrets.KEKProvider
+}
+
+// NewFileStorage opens (creating if necessary) the JSON file at path.
+func NewFileStorage(path string) (*FileStorage, error) {
+	s := &FileStorage{
+		path:       path,
+		namespaces: make(map[string]*namespace),
+		topic:      pubsub.NewTopic(watchBacklog),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStorage) load() error {
+	raw, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("storage: reading %s: %w", s.path, err)
+	}
+	var contents fileContents
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		return fmt.Errorf("storage: parsing %s: %w", s.path, err)
+	}
+	s.namespaces = contents.Namespaces
+	if s.namespaces == nil {
+		s.namespaces = make(map[string]*namespace)
+	}
+	for name, ns := range s.namespaces {
+		if ns.Keys == nil {
+			ns.Keys = make(map[string]*keyHistory)
+		}
+		if len(ns.Schema) > 0 {
+			if err := compileSchema(ns); err != nil {
+				return fmt.Errorf("storage: recompiling schema for namespace %q: %w", name, err)
+			}
+		}
+	}
+	s.revision = contents.Revision
+	return nil
+}
+
+// persist must be called with s.mu held.
+func (s *FileStorage) persist() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("storage: creating directory for %s: %w", s.path, err)
+	}
+	buf, err := json.MarshalIndent(fileContents{Revision: s.revision, Namespaces: s.namespaces}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return fmt.Errorf("storage: writing %s: %w", tmp, err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *FileStorage) namespaceOrCreate(name string) *namespace {
+	ns, ok := s.namespaces[name]
+	if !ok {
+		ns = &namespace{Keys: make(map[string]*keyHistory)}
+		s.namespaces[name] = ns
+	}
+	return ns
+}
+
+// resolve walks ns's inheritance chain (ns -> parent -> parent's parent...)
+// looking for key, returning its history from the first namespace that
+// defines it.
+func (s *FileStorage) resolve(ns, key string) (*keyHistory, bool) {
+	seen := 0
+	for ns != "" && seen < maxLookupDepth {
+		if n, ok := s.namespaces[ns]; ok {
+			if h, ok := n.Keys[key]; ok {
+				if _, live := h.current(); live {
+					return h, true
+				}
+				return nil, false
+			}
+			ns = n.Parent
+			seen++
+			continue
+		}
+		break
+	}
+	return nil, false
+}
+
+// SecretMask is returned in place of a secret version's plaintext by Get,
+// GetAtRevision and GetAtTime. Only Reveal returns the real value.
+const SecretMask = "***"
+
+func displayValue(v Version) string {
+	if v.Secret {
+		return SecretMask
+	}
+	return v.Value
+}
+
+// Get returns the current value for key in namespace, falling back through
+// the namespace's inheritance chain if unset locally. Secret values are
+// masked; use Reveal to decrypt them.
+func (s *FileStorage) Get(ctx context.Context, ns, key string) (string, bool, error) {
+	_, span := tracer.Start(ctx, "storage.Get")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if h, ok := s.resolve(ns, key); ok {
+		v, _ := h.current()
+		return displayValue(v), true, nil
+	}
+	return "", false, nil
+}
+
+// GetAtRevision returns key's value as of the given global revision,
+// looked up only within ns (inheritance is not applied for historical
+// reads, since a parent's state at that revision is ambiguous). Secret
+// values are masked; Reveal decrypts only a key's current value.
+func (s *FileStorage) GetAtRevision(ns, key string, revision uint64) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, ok := s.namespaces[ns]
+	if !ok {
+		return "", false, nil
+	}
+	h, ok := n.Keys[key]
+	if !ok {
+		return "", false, nil
+	}
+	v, ok := h.atRevision(revision)
+	return displayValue(v), ok, nil
+}
+
+// GetAtTime returns key's value as it stood at the given instant. Secret
+// values are masked.
+func (s *FileStorage) GetAtTime(ns, key string, at time.Time) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, ok := s.namespaces[ns]
+	if !ok {
+		return "", false, nil
+	}
+	h, ok := n.Keys[key]
+	if !ok {
+		return "", false, nil
+	}
+	v, ok := h.atTime(at)
+	return displayValue(v), ok, nil
+}
+
+// Set writes ns/key=value as a new immutable Version, validating against
+// the namespace's JSON Schema (if any) first, and publishes a change event
+// carrying the new revision.
+func (s *FileStorage) Set(ctx context.Context, ns, key, value, author, message string) error {
+	_, span := tracer.Start(ctx, "storage.Set")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.namespaceOrCreate(ns)
+	if n.schema != nil {
+		if err := validateAgainstSchema(n.schema, value); err != nil {
+			return err
+		}
+	}
+
+	s.revision++
+	h, ok := n.Keys[key]
+	if !ok {
+		h = &keyHistory{}
+		n.Keys[key] = h
+	}
+	h.Versions = append(h.Versions, Version{
+		Revision:  s.revision,
+		Value:     value,
+		Author:    author,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+
+	if err := s.persist(); err != nil {
+		return err
+	}
+	s.topic.Publish(EventPut, ns+"/"+key, value)
+	return nil
+}
+
+// SetKEKProvider configures the key-encryption key used by SetSecret,
+// Reveal and RotateKEK. It must be called once during startup before any
+// secret is written or read; main.go selects the provider via config.Load.
+func (s *FileStorage) SetKEKProvider(kek secrets.KEKProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kek = kek
+}
+
+// SetSecret encrypts value with the configured KEKProvider via envelope
+// encryption and writes it as a new immutable Version, same as Set except
+// the plaintext is never persisted to disk.
+func (s *FileStorage) SetSecret(ctx context.Context, ns, key, value, author, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.kek == nil {
+		return fmt.Errorf("storage: no KEK provider configured, cannot store secret")
+	}
+	env, err := secrets.Seal(ctx, s.kek, []byte(value))
+	if err != nil {
+		return err
+	}
+
+	n := s.namespaceOrCreate(ns)
+	s.revision++
+	h, ok := n.Keys[key]
+	if !ok {
+		h = &keyHistory{}
+		n.Keys[key] = h
+	}
+	h.Versions = append(h.Versions, Version{
+		Revision:  s.revision,
+		Secret:    true,
+		Envelope:  env,
+		Author:    author,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+
+	if err := s.persist(); err != nil {
+		return err
+	}
+	s.topic.Publish(EventPut, ns+"/"+key, SecretMask)
+	return nil
+}
+
+// Reveal decrypts the current value of ns/key. Callers are responsible for
+// RBAC-gating and auditing access to this method; see the configuration-
+// service API's /configs/:key/reveal handler.
+func (s *FileStorage) Reveal(ctx context.Context, ns, key string) (string, bool, error) {
+	s.mu.RLock()
+	h, ok := s.resolve(ns, key)
+	kek := s.kek
+	s.mu.RUnlock()
+	if !ok {
+		return "", false, nil
+	}
+
+	v, _ := h.current()
+	if !v.Secret {
+		return v.Value, true, nil
+	}
+	if kek == nil {
+		return "", false, fmt.Errorf("storage: no KEK provider configured, cannot reveal secret")
+	}
+	plaintext, err := secrets.Open(ctx, kek, v.Envelope)
+	if err != nil {
+		return "", false, err
+	}
+	return string(plaintext), true, nil
+}
+
+// RotateKEK re-wraps every secret's DEK under newKEK, leaving ciphertexts
+// and history untouched, then makes newKEK the active provider for future
+// writes and reveals.
+func (s *FileStorage) RotateKEK(ctx context.Context, newKEK secrets.KEKProvider) (rewrapped int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.kek == nil {
+		return 0, fmt.Errorf("storage: no existing KEK provider to rotate from")
+	}
+	oldKEK := s.kek
+
+	for _, n := range s.namespaces {
+		for _, h := range n.Keys {
+			for i := range h.Versions {
+				v := &h.Versions[i]
+				if !v.Secret || v.Deleted {
+					continue
+				}
+				newEnv, err := secrets.Rewrap(ctx, oldKEK, newKEK, v.Envelope)
+				if err != nil {
+					return rewrapped, fmt.Errorf("storage: rewrapping revision %d: %w", v.Revision, err)
+				}
+				v.Envelope = newEnv
+				rewrapped++
+			}
+		}
+	}
+
+	s.kek = newKEK
+	if err := s.persist(); err != nil {
+		return rewrapped, err
+	}
+	return rewrapped, nil
+}
+
+// Delete tombstones key in ns, if present, and publishes a delete event.
+// The key's history is retained so History/Diff/rollback still work.
+func (s *FileStorage) Delete(ctx context.Context, ns, key, author, message string) error {
+	_, span := tracer.Start(ctx, "storage.Delete")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.namespaces[ns]
+	if !ok {
+		return nil
+	}
+	h, ok := n.Keys[key]
+	if !ok {
+		return nil
+	}
+	if _, live := h.current(); !live {
+		return nil
+	}
+
+	s.revision++
+	h.Versions = append(h.Versions, Version{
+		Revision:  s.revision,
+		Author:    author,
+		Message:   message,
+		Timestamp: time.Now(),
+		Deleted:   true,
+	})
+
+	if err := s.persist(); err != nil {
+		return err
+	}
+	s.topic.Publish(EventDelete, ns+"/"+key, nil)
+	return nil
+}
+
+// List returns every live key/value pair visible in ns, including those
+// inherited from its parent chain (keys set directly in ns win).
+func (s *FileStorage) List(ctx context.Context, ns string) (map[string]string, error) {
+	_, span := tracer.Start(ctx, "storage.List")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string]string)
+	seen := 0
+	for ns != "" && seen < maxLookupDepth {
+		n, ok := s.namespaces[ns]
+		if !ok {
+			break
+		}
+		for key, h := range n.Keys {
+			if _, already := out[key]; already {
+				continue
+			}
+			if v, live := h.current(); live {
+				out[key] = displayValue(v)
+			}
+		}
+		ns = n.Parent
+		seen++
+	}
+	return out, nil
+}
+
+// History returns every version ever written for ns/key, oldest first.
+func (s *FileStorage) History(ns, key string) ([]Version, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, ok := s.namespaces[ns]
+	if !ok {
+		return nil, nil
+	}
+	h, ok := n.Keys[key]
+	if !ok {
+		return nil, nil
+	}
+	out := make([]Version, len(h.Versions))
+	copy(out, h.Versions)
+	return out, nil
+}
+
+// Rollback writes the value that ns/key held at toRevision as a brand new
+// version, preserving history rather than truncating it.
+func (s *FileStorage) Rollback(ctx context.Context, ns, key string, toRevision uint64, author, message string) (Version, error) {
+	value, ok, err := s.GetAtRevision(ns, key, toRevision)
+	if err != nil {
+		return Version{}, err
+	}
+	if !ok {
+		return Version{}, fmt.Errorf("storage: %s/%s has no live value at revision %d", ns, key, toRevision)
+	}
+	if message == "" {
+		message = fmt.Sprintf("rollback to revision %d", toRevision)
+	}
+	if err := s.Set(ctx, ns, key, value, author, message); err != nil {
+		return Version{}, err
+	}
+	v, _, err := s.latestVersion(ns, key)
+	return v, err
+}
+
+func (s *FileStorage) latestVersion(ns, key string) (Version, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, ok := s.namespaces[ns]
+	if !ok {
+		return Version{}, false, nil
+	}
+	h, ok := n.Keys[key]
+	if !ok || len(h.Versions) == 0 {
+		return Version{}, false, nil
+	}
+	return h.Versions[len(h.Versions)-1], true, nil
+}
+
+// Diff describes the change between two revisions of a key.
+type Diff struct {
+	From Version `json:"from"`
+	To   Version `json:"to"`
+}
+
+// Diff compares ns/key at revisions from and to.
+func (s *FileStorage) Diff(ns, key string, from, to uint64) (Diff, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.namespaces[ns]
+	if !ok {
+		return Diff{}, fmt.Errorf("storage: unknown namespace %q", ns)
+	}
+	h, ok := n.Keys[key]
+	if !ok {
+		return Diff{}, fmt.Errorf("storage: unknown key %q in namespace %q", key, ns)
+	}
+
+	fromV, _ := h.atRevision(from)
+	toV, _ := h.atRevision(to)
+	return Diff{From: fromV, To: toV}, nil
+}
+
+// SetNamespaceParent configures ns to inherit unset keys from parent.
+func (s *FileStorage) SetNamespaceParent(ns, parent string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.namespaceOrCreate(ns)
+	n.Parent = parent
+	return s.persist()
+}
+
+// RegisterSchema compiles and attaches a JSON Schema to ns; every
+// subsequent Set into ns must produce a value that validates against it.
+// Existing values are not retroactively validated.
+func (s *FileStorage) RegisterSchema(ns string, schemaJSON []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.namespaceOrCreate(ns)
+	n.Schema = schemaJSON
+	if err := compileSchema(n); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+func compileSchema(n *namespace) error {
+	loader := gojsonschema.NewBytesLoader(n.Schema)
+	schema, err := gojsonschema.NewSchema(loader)
+	if err != nil {
+		return fmt.Errorf("storage: compiling schema: %w", err)
+	}
+	n.schema = schema
+	return nil
+}
+
+// validateAgainstSchema checks a raw config value, parsed as a JSON
+// document, against the namespace's compiled schema. A value that is not
+// valid JSON (e.g. an unquoted plain string) is rejected.
+func validateAgainstSchema(schema *gojsonschema.Schema, value string) error {
+	result, err := schema.Validate(gojsonschema.NewStringLoader(value))
+	if err != nil {
+		return fmt.Errorf("storage: value is not valid JSON for schema validation: %w", err)
+	}
+	if !result.Valid() {
+		var msgs []string
+		for _, e := range result.Errors() {
+			msgs = append(msgs, e.String())
+		}
+		return fmt.Errorf("storage: value failed schema validation: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// Close flushes any pending writes to disk. Set/Delete/SetSecret already
+// persist synchronously, so this is a best-effort final write rather than
+// a requirement for durability; it exists so main.go can treat storage the
+// same way it treats the registry during graceful shutdown.
+func (s *FileStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.persist()
+}
+
+// Revision returns the store's current global revision.
+func (s *FileStorage) Revision() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revision
+}
+
+// Watch streams change events for ns/key pairs matching prefix (applied to
+// the "namespace/key" watch key), starting after fromRevision (0 to only
+// see future changes). The returned cancel func must be called once the
+// caller is done watching.
+func (s *FileStorage) Watch(ctx context.Context, prefix string, fromRevision uint64) (<-chan pubsub.Event, func()) {
+	if fromRevision == 0 {
+		fromRevision = s.topic.Revision()
+	}
+	events, cancel := s.topic.Subscribe(ctx, fromRevision)
+	if prefix == "" {
+		return events, cancel
+	}
+
+	filtered := make(chan pubsub.Event, 64)
+	go func() {
+		defer close(filtered)
+		for event := range events {
+			if strings.HasPrefix(event.Key, prefix) {
+				select {
+				case filtered <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return filtered, cancel
+}
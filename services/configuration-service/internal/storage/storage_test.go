@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStorage(t *testing.T) *FileStorage {
+	t.Helper()
+	s, err := NewFileStorage(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("NewFileStorage: %v", err)
+	}
+	return s
+}
+
+func TestKeyHistoryAtRevision(t *testing.T) {
+	h := &keyHistory{Versions: []Version{
+		{Revision: 1, Value: "v1"},
+		{Revision: 3, Value: "v3"},
+		{Revision: 5, Value: "v5"},
+	}}
+
+	v, ok := h.atRevision(4)
+	if !ok || v.Value != "v3" {
+		t.Fatalf("atRevision(4) = %+v, %v; want v3, true", v, ok)
+	}
+
+	v, ok = h.atRevision(1)
+	if !ok || v.Value != "v1" {
+		t.Fatalf("atRevision(1) = %+v, %v; want v1, true", v, ok)
+	}
+
+	if _, ok := h.atRevision(0); ok {
+		t.Fatal("atRevision(0) should find nothing before the first revision")
+	}
+}
+
+func TestKeyHistoryAtRevisionSkipsDeletedTip(t *testing.T) {
+	h := &keyHistory{Versions: []Version{
+		{Revision: 1, Value: "v1"},
+		{Revision: 2, Deleted: true},
+	}}
+
+	if _, ok := h.atRevision(2); ok {
+		t.Fatal("atRevision should report not-found once the key was deleted by that revision")
+	}
+	if v, ok := h.atRevision(1); !ok || v.Value != "v1" {
+		t.Fatalf("atRevision(1) = %+v, %v; want v1, true", v, ok)
+	}
+}
+
+func TestKeyHistoryAtTime(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := &keyHistory{Versions: []Version{
+		{Revision: 1, Value: "v1", Timestamp: t0},
+		{Revision: 2, Value: "v2", Timestamp: t0.Add(time.Hour)},
+	}}
+
+	v, ok := h.atTime(t0.Add(30 * time.Minute))
+	if !ok || v.Value != "v1" {
+		t.Fatalf("atTime(+30m) = %+v, %v; want v1, true", v, ok)
+	}
+
+	v, ok = h.atTime(t0.Add(2 * time.Hour))
+	if !ok || v.Value != "v2" {
+		t.Fatalf("atTime(+2h) = %+v, %v; want v2, true", v, ok)
+	}
+
+	if _, ok := h.atTime(t0.Add(-time.Minute)); ok {
+		t.Fatal("atTime before the first version should find nothing")
+	}
+}
+
+func TestNamespaceInheritanceFallsBackToParent(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	if err := s.Set(ctx, "dev", "shared.key", "dev-value", "tester", "seed"); err != nil {
+		t.Fatalf("Set(dev): %v", err)
+	}
+	if err := s.SetNamespaceParent("staging", "dev"); err != nil {
+		t.Fatalf("SetNamespaceParent: %v", err)
+	}
+
+	value, ok, err := s.Get(ctx, "staging", "shared.key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !ok || value != "dev-value" {
+		t.Fatalf("Get(staging, shared.key) = %q, %v; want dev-value, true", value, ok)
+	}
+}
+
+func TestNamespaceInheritanceLocalOverridesParent(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	if err := s.Set(ctx, "dev", "shared.key", "dev-value", "tester", "seed"); err != nil {
+		t.Fatalf("Set(dev): %v", err)
+	}
+	if err := s.SetNamespaceParent("staging", "dev"); err != nil {
+		t.Fatalf("SetNamespaceParent: %v", err)
+	}
+	if err := s.Set(ctx, "staging", "shared.key", "staging-value", "tester", "override"); err != nil {
+		t.Fatalf("Set(staging): %v", err)
+	}
+
+	value, ok, err := s.Get(ctx, "staging", "shared.key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !ok || value != "staging-value" {
+		t.Fatalf("Get(staging, shared.key) = %q, %v; want staging-value, true", value, ok)
+	}
+}
+
+func TestNamespaceInheritanceStopsAtDeletedLocalVersion(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	if err := s.Set(ctx, "dev", "shared.key", "dev-value", "tester", "seed"); err != nil {
+		t.Fatalf("Set(dev): %v", err)
+	}
+	if err := s.SetNamespaceParent("staging", "dev"); err != nil {
+		t.Fatalf("SetNamespaceParent: %v", err)
+	}
+	if err := s.Set(ctx, "staging", "shared.key", "staging-value", "tester", "override"); err != nil {
+		t.Fatalf("Set(staging): %v", err)
+	}
+	if err := s.Delete(ctx, "staging", "shared.key", "tester", "remove override"); err != nil {
+		t.Fatalf("Delete(staging): %v", err)
+	}
+
+	if _, ok, err := s.Get(ctx, "staging", "shared.key"); err != nil || ok {
+		t.Fatalf("Get after local delete = ok:%v, err:%v; want false, nil (delete must not fall through to parent)", ok, err)
+	}
+}
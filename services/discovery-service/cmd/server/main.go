@@ -1,49 +1,150 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"net"
 	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/van1sh/Mirage/pkg/serverkit"
 	"github.com/van1sh/Mirage/services/discovery-service/internal/api"
+	"github.com/van1sh/Mirage/services/discovery-service/internal/api/discoverypb"
 	"github.com/van1sh/Mirage/services/discovery-service/internal/config"
+	"github.com/van1sh/Mirage/services/discovery-service/internal/dns"
 	"github.com/van1sh/Mirage/services/discovery-service/internal/registry"
 )
 
+const serviceName = "discovery-service"
+
+// newBackend constructs the registry.Backend selected by cfg.RegistryBackend.
+func newBackend(cfg *config.Config) (registry.Backend, error) {
+	switch cfg.RegistryBackend {
+	case config.BackendMemory:
+		return registry.NewMemoryBackend(), nil
+	case config.BackendBolt:
+		return registry.NewBoltBackend(cfg.BoltPath)
+	case config.BackendBadger:
+		return registry.NewBadgerBackend(cfg.BadgerPath)
+	case config.BackendEtcd:
+		return registry.NewEtcdBackend(cfg.EtcdEndpoints, 5*time.Second)
+	case config.BackendConsul:
+		return registry.NewConsulBackend(cfg.ConsulAddr)
+	case config.BackendRaft:
+		return registry.NewRaftBackend(registry.RaftConfig{
+			NodeID:    cfg.RaftNodeID,
+			BindAddr:  cfg.RaftBindAddr,
+			DataDir:   cfg.RaftDataDir,
+			Bootstrap: cfg.RaftBootstrap,
+			Peers:     cfg.RaftPeers,
+		})
+	default:
+		return nil, fmt.Errorf("unknown registry backend %q", cfg.RegistryBackend)
+	}
+}
+
 func main() {
-	// Initialize logger
-	log.Println("Starting discovery service...")
+	logger, err := serverkit.NewLogger(serviceName)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize logger: %v", err))
+	}
+	defer logger.Sync() //nolint:errcheck
+
+	logger.Info("starting discovery service")
+
+	shutdownTracing, err := serverkit.InitTracing(context.Background(), serviceName)
+	if err != nil {
+		logger.Fatal("failed to initialize tracing", zap.Error(err))
+	}
 
 	// Load application config
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logger.Fatal("failed to load configuration", zap.Error(err))
 	}
 
 	// Initialize service registry
-	reg := registry.NewInMemoryRegistry(time.Duration(cfg.HeartbeatTimeoutSec) * time.Second)
+	backend, err := newBackend(cfg)
+	if err != nil {
+		logger.Fatal("failed to initialize registry backend", zap.Error(err))
+	}
+	reg := registry.NewRegistry(backend, time.Duration(cfg.HeartbeatTimeoutSec)*time.Second)
 
-	// Setup periodic cleanup of stale services
+	// Start active health checking for any instance that registers with a
+	// non-empty Checks list, alongside the existing heartbeat-based TTL.
+	healthChecker := registry.NewHealthChecker(reg, cfg.MaxConcurrentChecks, cfg.EnableExecChecks)
+	healthCtx, stopHealthChecks := context.WithCancel(context.Background())
+	go healthChecker.Run(healthCtx)
+
+	// Setup periodic cleanup of stale services. On replicated backends
+	// (raft, etcd, consul) this is safe to run on every replica: raft only
+	// acts on this while it holds leadership, and etcd/consul each enforce
+	// the same single-actor guarantee via a short-lived distributed lock.
+	cleanupDone := make(chan struct{})
+	cleanupStop := make(chan struct{})
 	go func() {
+		defer close(cleanupDone)
 		ticker := time.NewTicker(30 * time.Second)
 		defer ticker.Stop()
 
-		for range ticker.C {
-			reg.CleanupStaleServices()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := reg.CleanupStaleServices(); err != nil {
+					logger.Warn("stale service cleanup failed", zap.Error(err))
+				}
+			case <-cleanupStop:
+				return
+			}
 		}
 	}()
 
 	// Setup Gin router
-	router := gin.Default()
+	router := gin.New()
 
 	// Register middleware
 	router.Use(gin.Recovery())
 	router.Use(api.CORSMiddleware())
-	router.Use(api.RequestLogger())
+	router.Use(serverkit.Tracing(serviceName))
+	router.Use(serverkit.RequestLogger(logger))
 
 	// Register routes
-	api.RegisterRoutes(router, reg)
+	api.RegisterRoutes(router, reg, cfg)
+
+	// Start the gRPC watch server alongside the HTTP API so clients can
+	// subscribe to registration/deregistration changes over either transport.
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9093"
+	}
+	grpcServer := grpc.NewServer()
+	go func() {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			logger.Fatal("failed to listen for gRPC", zap.String("port", grpcPort), zap.Error(err))
+		}
+		discoverypb.RegisterDiscoveryWatchServer(grpcServer, api.NewWatchServer(reg))
+		logger.Info("discovery service gRPC watch listening", zap.String("port", grpcPort))
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.Fatal("gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	// Start the DNS interface alongside the HTTP API so unmodified resolvers
+	// can look up services under service.mirage without speaking HTTP.
+	dnsServer := dns.NewServer(reg, time.Duration(cfg.HeartbeatTimeoutSec)*time.Second, cfg.DNSAddr)
+	dnsErrs := make(chan error, 2)
+	dnsServer.Start(dnsErrs)
+	go func() {
+		for err := range dnsErrs {
+			logger.Warn("dns server error", zap.Error(err))
+		}
+	}()
+	logger.Info("discovery service DNS listening", zap.String("addr", cfg.DNSAddr))
 
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
@@ -51,9 +152,31 @@ func main() {
 		port = "8093"
 	}
 
-	// Start the server
-	log.Printf("Discovery service listening on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	err = serverkit.Run(logger, ":"+port, router,
+		func(ctx context.Context) error {
+			close(cleanupStop)
+			<-cleanupDone
+			return nil
+		},
+		func(ctx context.Context) error {
+			grpcServer.GracefulStop()
+			return nil
+		},
+		func(ctx context.Context) error {
+			return dnsServer.Shutdown(ctx)
+		},
+		func(ctx context.Context) error {
+			stopHealthChecks()
+			return nil
+		},
+		func(ctx context.Context) error {
+			return reg.Close()
+		},
+		func(ctx context.Context) error {
+			return shutdownTracing(ctx)
+		},
+	)
+	if err != nil {
+		logger.Fatal("discovery service exited with error", zap.Error(err))
 	}
 }
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"github.com/van1sh/Mirage/services/discovery-service/internal/api/discoverypb"
+	"github.com/van1sh/Mirage/services/discovery-service/internal/registry"
+)
+
+// watchServer implements discoverypb.DiscoveryWatchServer, the gRPC
+// counterpart to the SSE endpoint registered in routes.go. Both surfaces
+// read from the same registry.Registry watch feed so a client can pick
+// whichever transport fits it best.
+type watchServer struct {
+	discoverypb.UnimplementedDiscoveryWatchServer
+	reg *registry.Registry
+}
+
+// NewWatchServer returns a gRPC DiscoveryWatchServer backed by reg.
+func NewWatchServer(reg *registry.Registry) discoverypb.DiscoveryWatchServer {
+	return &watchServer{reg: reg}
+}
+
+func (s *watchServer) Watch(req *discoverypb.WatchRequest, stream discoverypb.DiscoveryWatch_WatchServer) error {
+	events, cancel := s.reg.Watch(stream.Context(), req.GetName(), req.GetTag(), req.GetFromRevision())
+	defer cancel()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			out := &discoverypb.WatchEvent{
+				Revision:    event.Revision,
+				Type:        event.Type,
+				ServiceName: event.Key,
+			}
+			if svc, ok := event.Value.(*registry.ServiceInstance); ok && svc != nil {
+				out.InstanceId = svc.ID
+				out.Instance = &discoverypb.ServiceInstance{
+					Id:       svc.ID,
+					Name:     svc.Name,
+					Address:  svc.Address,
+					Port:     int32(svc.Port),
+					Tags:     svc.Tags,
+					Metadata: svc.Metadata,
+				}
+			}
+			if err := stream.Send(out); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package api wires the discovery-service HTTP surface: registration,
+// lookup, and cluster observability endpoints backed by internal/registry.
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/van1sh/Mirage/services/discovery-service/internal/config"
+	"github.com/van1sh/Mirage/services/discovery-service/internal/registry"
+)
+
+// CORSMiddleware allows the service to be queried from browser-based
+// dashboards without a proxy.
+func CORSMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RegisterRoutes mounts the discovery-service API onto router.
+func RegisterRoutes(router *gin.Engine, reg *registry.Registry, cfg *config.Config) {
+	router.POST("/services/:name/:id", registerHandler(reg, cfg))
+	router.PUT("/services/:name/:id/heartbeat", heartbeatHandler(reg))
+	router.DELETE("/services/:name/:id", deregisterHandler(reg))
+	router.GET("/services/:name", lookupHandler(reg))
+	router.GET("/services", listHandler(reg))
+	router.GET("/services/:name/watch", watchServiceHandler(reg))
+	router.GET("/watch", watchAllHandler(reg))
+	router.GET("/services/:name/health", watchHealthHandler(reg))
+	router.GET("/cluster", clusterHandler(reg))
+}
+
+// onlyHealthy filters instances down to Healthy() ones when the caller set
+// ?healthy=true; any other value (including absent) returns instances
+// unfiltered.
+func onlyHealthy(c *gin.Context, instances []*registry.ServiceInstance) []*registry.ServiceInstance {
+	if c.Query("healthy") != "true" {
+		return instances
+	}
+	filtered := instances[:0]
+	for _, svc := range instances {
+		if svc.Healthy() {
+			filtered = append(filtered, svc)
+		}
+	}
+	return filtered
+}
+
+func registerHandler(reg *registry.Registry, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var svc registry.ServiceInstance
+		if err := c.ShouldBindJSON(&svc); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !cfg.EnableExecChecks {
+			for _, check := range svc.Checks {
+				if check.Type == registry.CheckExec {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "exec checks are disabled (set MIRAGE_ENABLE_EXEC_CHECKS=true to allow them)"})
+					return
+				}
+			}
+		}
+		svc.Name = c.Param("name")
+		svc.ID = c.Param("id")
+		if err := reg.Register(c.Request.Context(), &svc); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, svc)
+	}
+}
+
+func heartbeatHandler(reg *registry.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := reg.Heartbeat(c.Request.Context(), c.Param("name"), c.Param("id")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func deregisterHandler(reg *registry.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := reg.Deregister(c.Request.Context(), c.Param("name"), c.Param("id")); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func lookupHandler(reg *registry.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		instances, err := reg.Lookup(c.Request.Context(), c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, onlyHealthy(c, instances))
+	}
+}
+
+func listHandler(reg *registry.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		instances, err := reg.List(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, onlyHealthy(c, instances))
+	}
+}
+
+// clusterResponse is returned by GET /cluster. Fields are zero-valued when
+// the active backend is not clustered (e.g. memory/bolt/badger).
+type clusterResponse struct {
+	Clustered bool     `json:"clustered"`
+	Leader    string   `json:"leader,omitempty"`
+	IsLeader  bool     `json:"is_leader,omitempty"`
+	Peers     []string `json:"peers,omitempty"`
+	LastIndex uint64   `json:"last_index,omitempty"`
+}
+
+// parseFromRevision reads the `revision` query parameter clients use to
+// resume a watch after a disconnect.
+func parseFromRevision(c *gin.Context) uint64 {
+	v := c.Query("revision")
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func watchServiceHandler(reg *registry.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		streamWatch(c, reg, c.Param("name"))
+	}
+}
+
+func watchAllHandler(reg *registry.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		streamWatch(c, reg, c.Query("name"))
+	}
+}
+
+// streamWatch serves a Server-Sent Events stream of registry.Watch events,
+// optionally filtered by service name and/or tag, so clients can react to
+// registration changes without polling the lookup endpoints.
+func streamWatch(c *gin.Context, reg *registry.Registry, name string) {
+	events, cancel := reg.Watch(c.Request.Context(), name, c.Query("tag"), parseFromRevision(c))
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("change", event)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func watchHealthHandler(reg *registry.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		events, cancel := reg.WatchHealth(c.Request.Context(), c.Param("name"), parseFromRevision(c))
+		defer cancel()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return false
+				}
+				c.SSEvent("health", event)
+				return true
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	}
+}
+
+func clusterHandler(reg *registry.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info, ok := reg.Cluster()
+		if !ok {
+			c.JSON(http.StatusOK, clusterResponse{Clustered: false})
+			return
+		}
+		c.JSON(http.StatusOK, clusterResponse{
+			Clustered: true,
+			Leader:    info.Leader(),
+			IsLeader:  info.IsLeader(),
+			Peers:     info.Peers(),
+			LastIndex: info.LastIndex(),
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package config loads discovery-service configuration from the process
+// environment. There is no config file format yet; every setting is read
+// via os.Getenv with a sane local-development default so the service runs
+// out of the box with MIRAGE_REGISTRY_BACKEND=memory.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Backend names accepted by MIRAGE_REGISTRY_BACKEND.
+const (
+	BackendMemory = "memory"
+	BackendBolt   = "bolt"
+	BackendBadger = "badger"
+	BackendEtcd   = "etcd"
+	BackendConsul = "consul"
+	BackendRaft   = "raft"
+)
+
+// Config holds everything main.go needs to assemble the registry and
+// start the HTTP/DNS listeners.
+type Config struct {
+	HeartbeatTimeoutSec int
+
+	// RegistryBackend selects the registry.Backend implementation. See the
+	// Backend* constants above.
+	RegistryBackend string
+
+	// BoltPath / BadgerPath are used when RegistryBackend is "bolt"/"badger".
+	BoltPath   string
+	BadgerPath string
+
+	// EtcdEndpoints / ConsulAddr configure the external-coordination backends.
+	EtcdEndpoints []string
+	ConsulAddr    string
+
+	// Raft* configure the self-hosted HA backend.
+	RaftNodeID    string
+	RaftBindAddr  string
+	RaftDataDir   string
+	RaftBootstrap bool
+	RaftPeers     []string
+
+	// MaxConcurrentChecks bounds how many active health checks the
+	// HealthChecker runs at once, across every instance and check type.
+	MaxConcurrentChecks int
+
+	// EnableExecChecks allows registrants to attach a "exec" health check,
+	// which the HealthChecker runs as an arbitrary host command on this
+	// service's interval. Since registration is unauthenticated, this is
+	// effectively remote code execution for anyone who can reach the
+	// registration endpoint, so it defaults to off (mirroring Consul's
+	// enable_local_script_checks). Exec checks submitted while this is
+	// false are rejected at registration time.
+	EnableExecChecks bool
+
+	// DNSAddr is the UDP/TCP address (host:port) the internal/dns server
+	// binds to, answering A/AAAA/SRV queries under service.mirage.
+	DNSAddr string
+}
+
+// Load reads Config from the environment, applying defaults that mirror the
+// service's original zero-configuration (in-memory) behavior.
+func Load() (*Config, error) {
+	cfg := &Config{
+		HeartbeatTimeoutSec: 30,
+		RegistryBackend:     BackendMemory,
+		BoltPath:            "data/discovery-registry.db",
+		BadgerPath:          "data/discovery-registry-badger",
+		ConsulAddr:          "127.0.0.1:8500",
+		RaftNodeID:          "node1",
+		RaftBindAddr:        "127.0.0.1:7000",
+		RaftDataDir:         "data/raft",
+		MaxConcurrentChecks: 16,
+		DNSAddr:             ":8600",
+	}
+
+	if v := os.Getenv("MIRAGE_HEARTBEAT_TIMEOUT_SEC"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid MIRAGE_HEARTBEAT_TIMEOUT_SEC: %w", err)
+		}
+		cfg.HeartbeatTimeoutSec = n
+	}
+
+	if v := os.Getenv("MIRAGE_REGISTRY_BACKEND"); v != "" {
+		cfg.RegistryBackend = v
+	}
+	switch cfg.RegistryBackend {
+	case BackendMemory, BackendBolt, BackendBadger, BackendEtcd, BackendConsul, BackendRaft:
+	default:
+		return nil, fmt.Errorf("config: unknown MIRAGE_REGISTRY_BACKEND %q", cfg.RegistryBackend)
+	}
+
+	if v := os.Getenv("MIRAGE_BOLT_PATH"); v != "" {
+		cfg.BoltPath = v
+	}
+	if v := os.Getenv("MIRAGE_BADGER_PATH"); v != "" {
+		cfg.BadgerPath = v
+	}
+	if v := os.Getenv("MIRAGE_ETCD_ENDPOINTS"); v != "" {
+		cfg.EtcdEndpoints = strings.Split(v, ",")
+	}
+	if v := os.Getenv("MIRAGE_CONSUL_ADDR"); v != "" {
+		cfg.ConsulAddr = v
+	}
+
+	if v := os.Getenv("MIRAGE_RAFT_NODE_ID"); v != "" {
+		cfg.RaftNodeID = v
+	}
+	if v := os.Getenv("MIRAGE_RAFT_BIND_ADDR"); v != "" {
+		cfg.RaftBindAddr = v
+	}
+	if v := os.Getenv("MIRAGE_RAFT_DATA_DIR"); v != "" {
+		cfg.RaftDataDir = v
+	}
+	if v := os.Getenv("MIRAGE_RAFT_BOOTSTRAP"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid MIRAGE_RAFT_BOOTSTRAP: %w", err)
+		}
+		cfg.RaftBootstrap = b
+	}
+	if v := os.Getenv("MIRAGE_RAFT_PEERS"); v != "" {
+		cfg.RaftPeers = strings.Split(v, ",")
+	}
+
+	if v := os.Getenv("MIRAGE_MAX_CONCURRENT_CHECKS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid MIRAGE_MAX_CONCURRENT_CHECKS: %w", err)
+		}
+		cfg.MaxConcurrentChecks = n
+	}
+
+	if v := os.Getenv("MIRAGE_DNS_ADDR"); v != "" {
+		cfg.DNSAddr = v
+	}
+
+	if v := os.Getenv("MIRAGE_ENABLE_EXEC_CHECKS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid MIRAGE_ENABLE_EXEC_CHECKS: %w", err)
+		}
+		cfg.EnableExecChecks = b
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,273 @@
+// Package dns exposes the discovery-service registry over the DNS
+// protocol, following Consul's naming convention, so unmodified resolvers
+// (the OS stub resolver, `dig`, application DNS clients) can look up
+// services without speaking HTTP:
+//
+//	<service>.service.mirage           A/AAAA, healthy instance addresses
+//	<tag>.<service>.service.mirage      same, filtered to instances with <tag>
+//	_<port-name>._tcp.<service>.service.mirage  SRV, weighted by instance metadata
+//
+// Only healthy instances (registry.ServiceInstance.Healthy) are returned.
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/van1sh/Mirage/services/discovery-service/internal/registry"
+)
+
+// Domain is the fixed zone this server answers for.
+const Domain = "service.mirage."
+
+// defaultPortName is used for SRV lookups when an instance's metadata
+// doesn't specify one.
+const defaultPortName = "default"
+
+// Server answers DNS queries for services registered in an
+// *registry.Registry.
+type Server struct {
+	reg              *registry.Registry
+	heartbeatTimeout time.Duration
+	udp              *dns.Server
+	tcp              *dns.Server
+}
+
+// NewServer builds a DNS server bound to addr (e.g. ":8600") that resolves
+// names under Domain against reg. heartbeatTimeout is reused as the
+// returned records' TTL, since that's how long a registration is trusted
+// to still be live before CleanupStaleServices would remove it.
+func NewServer(reg *registry.Registry, heartbeatTimeout time.Duration, addr string) *Server {
+	s := &Server{reg: reg, heartbeatTimeout: heartbeatTimeout}
+
+	mux := dns.NewServeMux()
+	mux.HandleFunc(Domain, s.handleQuery)
+
+	s.udp = &dns.Server{Addr: addr, Net: "udp", Handler: mux}
+	s.tcp = &dns.Server{Addr: addr, Net: "tcp", Handler: mux}
+	return s
+}
+
+// Start launches the UDP and TCP listeners in their own goroutines. Any
+// error from either (including from the initial bind) is sent to errs.
+func (s *Server) Start(errs chan<- error) {
+	go func() {
+		if err := s.udp.ListenAndServe(); err != nil {
+			errs <- fmt.Errorf("dns: udp server: %w", err)
+		}
+	}()
+	go func() {
+		if err := s.tcp.ListenAndServe(); err != nil {
+			errs <- fmt.Errorf("dns: tcp server: %w", err)
+		}
+	}()
+}
+
+// Shutdown gracefully stops both listeners.
+func (s *Server) Shutdown(ctx context.Context) error {
+	udpErr := s.udp.ShutdownContext(ctx)
+	tcpErr := s.tcp.ShutdownContext(ctx)
+	if udpErr != nil {
+		return udpErr
+	}
+	return tcpErr
+}
+
+func (s *Server) handleQuery(w dns.ResponseWriter, r *dns.Msg) {
+	msg := new(dns.Msg)
+	msg.SetReply(r)
+	msg.Authoritative = true
+
+	if len(r.Question) != 1 {
+		msg.SetRcode(r, dns.RcodeFormatError)
+		_ = w.WriteMsg(msg)
+		return
+	}
+
+	q := r.Question[0]
+	switch q.Qtype {
+	case dns.TypeA, dns.TypeAAAA:
+		s.answerAddress(msg, q)
+	case dns.TypeSRV:
+		s.answerSRV(msg, q)
+	default:
+		msg.Rcode = dns.RcodeNotImplemented
+	}
+	_ = w.WriteMsg(msg)
+}
+
+func (s *Server) answerAddress(msg *dns.Msg, q dns.Question) {
+	service, tag, ok := parseServiceName(q.Name)
+	if !ok {
+		msg.Rcode = dns.RcodeNameError
+		return
+	}
+
+	ttl := s.ttl()
+	for _, svc := range s.healthyInstances(service, tag) {
+		ip := net.ParseIP(svc.Address)
+		if ip == nil {
+			continue
+		}
+		if q.Qtype == dns.TypeA {
+			if ip4 := ip.To4(); ip4 != nil {
+				msg.Answer = append(msg.Answer, &dns.A{
+					Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+					A:   ip4,
+				})
+			}
+		} else if ip.To4() == nil {
+			msg.Answer = append(msg.Answer, &dns.AAAA{
+				Hdr:  dns.RR_Header{Name: q.Name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: ttl},
+				AAAA: ip,
+			})
+		}
+	}
+	if len(msg.Answer) == 0 {
+		msg.Rcode = dns.RcodeNameError
+	}
+}
+
+func (s *Server) answerSRV(msg *dns.Msg, q dns.Question) {
+	portName, service, ok := parseSRVName(q.Name)
+	if !ok {
+		msg.Rcode = dns.RcodeNameError
+		return
+	}
+
+	ttl := s.ttl()
+	for _, svc := range s.healthyInstances(service, "") {
+		if instancePortName(svc) != portName {
+			continue
+		}
+
+		target := dns.Fqdn(fmt.Sprintf("%s.%s", svc.ID, service+"."+Domain))
+		msg.Answer = append(msg.Answer, &dns.SRV{
+			Hdr:      dns.RR_Header{Name: q.Name, Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: ttl},
+			Priority: 1,
+			Weight:   instanceWeight(svc),
+			Port:     uint16(svc.Port),
+			Target:   target,
+		})
+
+		ip := net.ParseIP(svc.Address)
+		if ip == nil {
+			continue
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			msg.Extra = append(msg.Extra, &dns.A{Hdr: dns.RR_Header{Name: target, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl}, A: ip4})
+		} else {
+			msg.Extra = append(msg.Extra, &dns.AAAA{Hdr: dns.RR_Header{Name: target, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: ttl}, AAAA: ip})
+		}
+	}
+	if len(msg.Answer) == 0 {
+		msg.Rcode = dns.RcodeNameError
+	}
+}
+
+// healthyInstances looks up service's live, healthy instances, optionally
+// filtered to those carrying tag.
+func (s *Server) healthyInstances(service, tag string) []*registry.ServiceInstance {
+	instances, err := s.reg.Lookup(context.Background(), service)
+	if err != nil {
+		return nil
+	}
+	out := instances[:0]
+	for _, svc := range instances {
+		if !svc.Healthy() {
+			continue
+		}
+		if tag != "" && !hasTag(svc.Tags, tag) {
+			continue
+		}
+		out = append(out, svc)
+	}
+	return out
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// instancePortName returns the metadata["port_name"] an instance answers
+// SRV lookups under, defaulting to defaultPortName.
+func instancePortName(svc *registry.ServiceInstance) string {
+	if name := svc.Metadata["port_name"]; name != "" {
+		return name
+	}
+	return defaultPortName
+}
+
+// instanceWeight returns an instance's SRV weight from metadata["weight"],
+// defaulting to 1 (equal weighting) if unset or unparsable.
+func instanceWeight(svc *registry.ServiceInstance) uint16 {
+	if raw, ok := svc.Metadata["weight"]; ok {
+		if w, err := strconv.ParseUint(raw, 10, 16); err == nil {
+			return uint16(w)
+		}
+	}
+	return 1
+}
+
+func (s *Server) ttl() uint32 {
+	if s.heartbeatTimeout <= 0 {
+		return 30
+	}
+	return uint32(s.heartbeatTimeout / time.Second)
+}
+
+// parseServiceName strips the Domain suffix from an A/AAAA query name and
+// splits what's left into an optional leading tag and the service name:
+//
+//	foo.service.mirage.      -> ("foo", "", true)
+//	canary.foo.service.mirage. -> ("foo", "canary", true)
+func parseServiceName(name string) (service, tag string, ok bool) {
+	labels, ok := stripDomain(name)
+	if !ok {
+		return "", "", false
+	}
+	switch len(labels) {
+	case 1:
+		return labels[0], "", true
+	case 2:
+		return labels[1], labels[0], true
+	default:
+		return "", "", false
+	}
+}
+
+// parseSRVName strips the Domain suffix from an SRV query name of the form
+// _<port-name>._tcp.<service>.service.mirage.
+func parseSRVName(name string) (portName, service string, ok bool) {
+	labels, ok := stripDomain(name)
+	if !ok || len(labels) != 3 {
+		return "", "", false
+	}
+	if !strings.HasPrefix(labels[0], "_") || labels[1] != "_tcp" {
+		return "", "", false
+	}
+	return strings.TrimPrefix(labels[0], "_"), labels[2], true
+}
+
+// stripDomain lower-cases name, verifies it falls under Domain, and
+// returns the remaining labels (closest to the root last).
+func stripDomain(name string) ([]string, bool) {
+	name = strings.ToLower(dns.Fqdn(name))
+	suffix := strings.ToLower(Domain)
+	if name == suffix || !strings.HasSuffix(name, suffix) {
+		return nil, false
+	}
+	prefix := strings.TrimSuffix(strings.TrimSuffix(name, suffix), ".")
+	return strings.Split(prefix, "."), true
+}
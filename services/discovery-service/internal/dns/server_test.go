@@ -0,0 +1,85 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripDomain(t *testing.T) {
+	cases := []struct {
+		name   string
+		labels []string
+		ok     bool
+	}{
+		{"foo.service.mirage.", []string{"foo"}, true},
+		{"foo.service.mirage", []string{"foo"}, true},
+		{"FOO.Service.Mirage.", []string{"foo"}, true},
+		{"canary.foo.service.mirage.", []string{"canary", "foo"}, true},
+		{"service.mirage.", nil, false},
+		{"foo.service.consul.", nil, false},
+	}
+	for _, c := range cases {
+		labels, ok := stripDomain(c.name)
+		if ok != c.ok {
+			t.Errorf("stripDomain(%q) ok = %v, want %v", c.name, ok, c.ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(labels, c.labels) {
+			t.Errorf("stripDomain(%q) = %v, want %v", c.name, labels, c.labels)
+		}
+	}
+}
+
+func TestParseServiceName(t *testing.T) {
+	cases := []struct {
+		name        string
+		wantService string
+		wantTag     string
+		wantOK      bool
+	}{
+		{"foo.service.mirage.", "foo", "", true},
+		{"canary.foo.service.mirage.", "foo", "canary", true},
+		{"a.b.foo.service.mirage.", "", "", false},
+		{"service.mirage.", "", "", false},
+	}
+	for _, c := range cases {
+		service, tag, ok := parseServiceName(c.name)
+		if ok != c.wantOK {
+			t.Errorf("parseServiceName(%q) ok = %v, want %v", c.name, ok, c.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if service != c.wantService || tag != c.wantTag {
+			t.Errorf("parseServiceName(%q) = (%q, %q), want (%q, %q)", c.name, service, tag, c.wantService, c.wantTag)
+		}
+	}
+}
+
+func TestParseSRVName(t *testing.T) {
+	cases := []struct {
+		name         string
+		wantPortName string
+		wantService  string
+		wantOK       bool
+	}{
+		{"_web._tcp.foo.service.mirage.", "web", "foo", true},
+		{"_web._udp.foo.service.mirage.", "", "", false},
+		{"web._tcp.foo.service.mirage.", "", "", false},
+		{"foo.service.mirage.", "", "", false},
+	}
+	for _, c := range cases {
+		portName, service, ok := parseSRVName(c.name)
+		if ok != c.wantOK {
+			t.Errorf("parseSRVName(%q) ok = %v, want %v", c.name, ok, c.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if portName != c.wantPortName || service != c.wantService {
+			t.Errorf("parseSRVName(%q) = (%q, %q), want (%q, %q)", c.name, portName, service, c.wantPortName, c.wantService)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package registry
+
+import (
+	"context"
+	"time"
+)
+
+// Backend is the storage and coordination layer used by Registry. It owns
+// the actual persistence of ServiceInstance records and, for clustered
+// implementations, replication across discovery-service replicas.
+//
+// Cleanup is given the TTL rather than a cutoff time so each backend can
+// apply it against its own notion of "now" (important for raft, where
+// cleanup must run against the leader's clock and log).
+type Backend interface {
+	Put(ctx context.Context, svc *ServiceInstance) error
+	Delete(ctx context.Context, name, id string) error
+	Get(ctx context.Context, name, id string) (*ServiceInstance, bool, error)
+	List(ctx context.Context, name string) ([]*ServiceInstance, error)
+	All(ctx context.Context) ([]*ServiceInstance, error)
+	Cleanup(ctx context.Context, ttl time.Duration) (removed int, err error)
+	Close() error
+}
@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// backendFactories lists the Backend implementations that can run the
+// shared contract test without external infra. etcd/consul/raft need a
+// live cluster; raft's state machine is covered by the fsm-level tests in
+// fsm_test.go, and etcd/consul have no tests here.
+func backendFactories(t *testing.T) map[string]func() Backend {
+	t.Helper()
+	return map[string]func() Backend{
+		"memory": func() Backend {
+			return NewMemoryBackend()
+		},
+		"bolt": func() Backend {
+			b, err := NewBoltBackend(filepath.Join(t.TempDir(), "bolt.db"))
+			if err != nil {
+				t.Fatalf("NewBoltBackend: %v", err)
+			}
+			return b
+		},
+		"badger": func() Backend {
+			b, err := NewBadgerBackend(filepath.Join(t.TempDir(), "badger"))
+			if err != nil {
+				t.Fatalf("NewBadgerBackend: %v", err)
+			}
+			return b
+		},
+	}
+}
+
+func TestBackendContract(t *testing.T) {
+	for name, newBackend := range backendFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			backend := newBackend()
+			defer backend.Close()
+			ctx := context.Background()
+
+			if _, ok, err := backend.Get(ctx, "web", "1"); err != nil || ok {
+				t.Fatalf("Get on empty backend = ok:%v, err:%v; want false, nil", ok, err)
+			}
+
+			svc := &ServiceInstance{Name: "web", ID: "1", Address: "10.0.0.1", Port: 8080, LastHeartbeat: time.Now()}
+			if err := backend.Put(ctx, svc); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+
+			got, ok, err := backend.Get(ctx, "web", "1")
+			if err != nil || !ok {
+				t.Fatalf("Get after Put = ok:%v, err:%v; want true, nil", ok, err)
+			}
+			if got.Address != svc.Address || got.Port != svc.Port {
+				t.Fatalf("Get = %+v, want %+v", got, svc)
+			}
+
+			if err := backend.Put(ctx, &ServiceInstance{Name: "web", ID: "2", Address: "10.0.0.2", Port: 8080, LastHeartbeat: time.Now()}); err != nil {
+				t.Fatalf("Put second instance: %v", err)
+			}
+			if err := backend.Put(ctx, &ServiceInstance{Name: "api", ID: "1", Address: "10.0.1.1", Port: 9090, LastHeartbeat: time.Now()}); err != nil {
+				t.Fatalf("Put other service: %v", err)
+			}
+
+			webInstances, err := backend.List(ctx, "web")
+			if err != nil {
+				t.Fatalf("List(web): %v", err)
+			}
+			if len(webInstances) != 2 {
+				t.Fatalf("List(web) returned %d instances, want 2", len(webInstances))
+			}
+
+			all, err := backend.All(ctx)
+			if err != nil {
+				t.Fatalf("All: %v", err)
+			}
+			if len(all) != 3 {
+				t.Fatalf("All returned %d instances, want 3", len(all))
+			}
+
+			if err := backend.Delete(ctx, "web", "1"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, ok, err := backend.Get(ctx, "web", "1"); err != nil || ok {
+				t.Fatalf("Get after Delete = ok:%v, err:%v; want false, nil", ok, err)
+			}
+
+			stale := &ServiceInstance{Name: "web", ID: "3", Address: "10.0.0.3", Port: 8080, LastHeartbeat: time.Now().Add(-time.Hour)}
+			if err := backend.Put(ctx, stale); err != nil {
+				t.Fatalf("Put stale instance: %v", err)
+			}
+			removed, err := backend.Cleanup(ctx, time.Minute)
+			if err != nil {
+				t.Fatalf("Cleanup: %v", err)
+			}
+			if removed != 1 {
+				t.Fatalf("Cleanup removed %d, want 1", removed)
+			}
+			if _, ok, err := backend.Get(ctx, "web", "3"); err != nil || ok {
+				t.Fatalf("Get after Cleanup = ok:%v, err:%v; want false, nil", ok, err)
+			}
+
+			remaining, err := backend.All(ctx)
+			if err != nil {
+				t.Fatalf("All after Cleanup: %v", err)
+			}
+			if len(remaining) != 2 {
+				t.Fatalf("All after Cleanup returned %d instances, want 2", len(remaining))
+			}
+		})
+	}
+}
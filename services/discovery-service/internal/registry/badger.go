@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// BadgerBackend persists instances in an embedded BadgerDB LSM store. Like
+// BoltBackend it is single-node: pick it over Bolt when write throughput
+// matters more than the simplicity of a single mmap'd file.
+type BadgerBackend struct {
+	db *badger.DB
+}
+
+// NewBadgerBackend opens (creating if necessary) a BadgerDB directory at path.
+func NewBadgerBackend(path string) (*BadgerBackend, error) {
+	opts := badger.DefaultOptions(path).WithLogger(nil)
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &BadgerBackend{db: db}, nil
+}
+
+func badgerKey(name, id string) []byte {
+	return []byte(name + "/" + id)
+}
+
+func (b *BadgerBackend) Put(_ context.Context, svc *ServiceInstance) error {
+	buf, err := json.Marshal(svc)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(badgerKey(svc.Name, svc.ID), buf)
+	})
+}
+
+func (b *BadgerBackend) Delete(_ context.Context, name, id string) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(badgerKey(name, id))
+	})
+}
+
+func (b *BadgerBackend) Get(_ context.Context, name, id string) (*ServiceInstance, bool, error) {
+	var svc *ServiceInstance
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(badgerKey(name, id))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			svc = &ServiceInstance{}
+			return json.Unmarshal(val, svc)
+		})
+	})
+	return svc, svc != nil, err
+}
+
+func (b *BadgerBackend) List(ctx context.Context, name string) ([]*ServiceInstance, error) {
+	var out []*ServiceInstance
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		prefix := []byte(name + "/")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			err := item.Value(func(val []byte) error {
+				svc := &ServiceInstance{}
+				if err := json.Unmarshal(val, svc); err != nil {
+					return err
+				}
+				out = append(out, svc)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (b *BadgerBackend) All(_ context.Context) ([]*ServiceInstance, error) {
+	var out []*ServiceInstance
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			err := it.Item().Value(func(val []byte) error {
+				svc := &ServiceInstance{}
+				if err := json.Unmarshal(val, svc); err != nil {
+					return err
+				}
+				out = append(out, svc)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (b *BadgerBackend) Cleanup(ctx context.Context, ttl time.Duration) (int, error) {
+	cutoff := time.Now().Add(-ttl)
+	all, err := b.All(ctx)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	err = b.db.Update(func(txn *badger.Txn) error {
+		for _, svc := range all {
+			if svc.LastHeartbeat.Before(cutoff) {
+				if err := txn.Delete(badgerKey(svc.Name, svc.ID)); err != nil {
+					return err
+				}
+				removed++
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+func (b *BadgerBackend) Close() error {
+	return b.db.Close()
+}
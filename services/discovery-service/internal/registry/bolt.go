@@ -0,0 +1,126 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var servicesBucket = []byte("services")
+
+// BoltBackend persists instances in a single embedded BoltDB file. It
+// survives process restarts but, like BadgerBackend, only runs on one node
+// at a time: BoltDB takes an exclusive file lock, so it is not suitable for
+// multi-replica deployments without an external backend (etcd/consul) or the
+// raft backend in front of it.
+type BoltBackend struct {
+	db *bolt.DB
+}
+
+// NewBoltBackend opens (creating if necessary) a BoltDB file at path.
+func NewBoltBackend(path string) (*BoltBackend, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("registry: opening bolt db: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(servicesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("registry: creating bolt bucket: %w", err)
+	}
+	return &BoltBackend{db: db}, nil
+}
+
+func boltKey(name, id string) []byte {
+	return []byte(name + "/" + id)
+}
+
+func (b *BoltBackend) Put(_ context.Context, svc *ServiceInstance) error {
+	buf, err := json.Marshal(svc)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(servicesBucket).Put(boltKey(svc.Name, svc.ID), buf)
+	})
+}
+
+func (b *BoltBackend) Delete(_ context.Context, name, id string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(servicesBucket).Delete(boltKey(name, id))
+	})
+}
+
+func (b *BoltBackend) Get(_ context.Context, name, id string) (*ServiceInstance, bool, error) {
+	var svc *ServiceInstance
+	err := b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(servicesBucket).Get(boltKey(name, id))
+		if raw == nil {
+			return nil
+		}
+		svc = &ServiceInstance{}
+		return json.Unmarshal(raw, svc)
+	})
+	return svc, svc != nil, err
+}
+
+func (b *BoltBackend) List(ctx context.Context, name string) ([]*ServiceInstance, error) {
+	all, err := b.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*ServiceInstance, 0, len(all))
+	for _, svc := range all {
+		if svc.Name == name {
+			out = append(out, svc)
+		}
+	}
+	return out, nil
+}
+
+func (b *BoltBackend) All(_ context.Context) ([]*ServiceInstance, error) {
+	var out []*ServiceInstance
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(servicesBucket).ForEach(func(_, raw []byte) error {
+			svc := &ServiceInstance{}
+			if err := json.Unmarshal(raw, svc); err != nil {
+				return err
+			}
+			out = append(out, svc)
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (b *BoltBackend) Cleanup(ctx context.Context, ttl time.Duration) (int, error) {
+	cutoff := time.Now().Add(-ttl)
+	all, err := b.All(ctx)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(servicesBucket)
+		for _, svc := range all {
+			if svc.LastHeartbeat.Before(cutoff) {
+				if err := bucket.Delete(boltKey(svc.Name, svc.ID)); err != nil {
+					return err
+				}
+				removed++
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+func (b *BoltBackend) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,147 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+const consulPrefix = "mirage/discovery/"
+
+// consulCleanupLockKey is held, via a Consul session, for the duration of
+// one Cleanup pass so that only one discovery-service replica sharing this
+// Consul cluster deletes stale entries at a time.
+const consulCleanupLockKey = consulPrefix + "_cleanup_lock"
+
+// ConsulBackend delegates storage to an external Consul KV store, using
+// Consul itself (rather than Mirage's own registry) as the source of truth
+// for cluster membership. Useful when Mirage is deployed alongside an
+// existing Consul install that operators already trust.
+type ConsulBackend struct {
+	client *consulapi.Client
+	kv     *consulapi.KV
+}
+
+// NewConsulBackend dials the Consul agent at addr (e.g. "127.0.0.1:8500").
+func NewConsulBackend(addr string) (*ConsulBackend, error) {
+	client, err := consulapi.NewClient(&consulapi.Config{Address: addr})
+	if err != nil {
+		return nil, err
+	}
+	return &ConsulBackend{client: client, kv: client.KV()}, nil
+}
+
+func consulKey(name, id string) string {
+	return consulPrefix + name + "/" + id
+}
+
+func (c *ConsulBackend) Put(_ context.Context, svc *ServiceInstance) error {
+	buf, err := json.Marshal(svc)
+	if err != nil {
+		return err
+	}
+	_, err = c.kv.Put(&consulapi.KVPair{Key: consulKey(svc.Name, svc.ID), Value: buf}, nil)
+	return err
+}
+
+func (c *ConsulBackend) Delete(_ context.Context, name, id string) error {
+	_, err := c.kv.Delete(consulKey(name, id), nil)
+	return err
+}
+
+func (c *ConsulBackend) Get(_ context.Context, name, id string) (*ServiceInstance, bool, error) {
+	pair, _, err := c.kv.Get(consulKey(name, id), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if pair == nil {
+		return nil, false, nil
+	}
+	svc := &ServiceInstance{}
+	if err := json.Unmarshal(pair.Value, svc); err != nil {
+		return nil, false, err
+	}
+	return svc, true, nil
+}
+
+func (c *ConsulBackend) List(_ context.Context, name string) ([]*ServiceInstance, error) {
+	pairs, _, err := c.kv.List(consulPrefix+name+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*ServiceInstance, 0, len(pairs))
+	for _, pair := range pairs {
+		svc := &ServiceInstance{}
+		if err := json.Unmarshal(pair.Value, svc); err != nil {
+			return nil, err
+		}
+		out = append(out, svc)
+	}
+	return out, nil
+}
+
+func (c *ConsulBackend) All(_ context.Context) ([]*ServiceInstance, error) {
+	pairs, _, err := c.kv.List(consulPrefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*ServiceInstance, 0, len(pairs))
+	for _, pair := range pairs {
+		svc := &ServiceInstance{}
+		if err := json.Unmarshal(pair.Value, svc); err != nil {
+			return nil, err
+		}
+		out = append(out, svc)
+	}
+	return out, nil
+}
+
+// Cleanup only runs while holding consulCleanupLockKey via a short-lived
+// Consul session: on every replica but the one that wins Acquire, this is a
+// no-op, mirroring RaftBackend.Cleanup's leader-only behavior without
+// Consul itself having a notion of "leader" for this application's
+// replicas.
+func (c *ConsulBackend) Cleanup(ctx context.Context, ttl time.Duration) (int, error) {
+	sessionID, _, err := c.client.Session().CreateNoChecks(&consulapi.SessionEntry{
+		Name:     "mirage-discovery-cleanup",
+		TTL:      "30s",
+		Behavior: consulapi.SessionBehaviorDelete,
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer c.client.Session().Destroy(sessionID, nil) //nolint:errcheck
+
+	acquired, _, err := c.kv.Acquire(&consulapi.KVPair{
+		Key:     consulCleanupLockKey,
+		Value:   []byte("locked"),
+		Session: sessionID,
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+	if !acquired {
+		return 0, nil
+	}
+	defer c.kv.Release(&consulapi.KVPair{Key: consulCleanupLockKey, Session: sessionID}, nil) //nolint:errcheck
+
+	all, err := c.All(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-ttl)
+	removed := 0
+	for _, svc := range all {
+		if svc.LastHeartbeat.Before(cutoff) {
+			if err := c.Delete(ctx, svc.Name, svc.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (c *ConsulBackend) Close() error { return nil }
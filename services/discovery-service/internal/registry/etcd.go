@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+const etcdPrefix = "/mirage/discovery/"
+
+// etcdCleanupLockKey is held for the duration of one Cleanup pass so that,
+// across however many discovery-service replicas share this etcd cluster,
+// only one of them actually deletes stale entries at a time.
+const etcdCleanupLockKey = etcdPrefix + "_cleanup_lock"
+
+// EtcdBackend delegates storage and replication to an external etcd
+// cluster. Multiple discovery-service replicas can point at the same etcd
+// cluster and observe a consistent view without running their own
+// consensus, unlike BoltBackend/BadgerBackend.
+type EtcdBackend struct {
+	client  *clientv3.Client
+	session *concurrency.Session
+}
+
+// NewEtcdBackend dials the etcd cluster at endpoints.
+func NewEtcdBackend(endpoints []string, dialTimeout time.Duration) (*EtcdBackend, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: dialTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	session, err := concurrency.NewSession(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &EtcdBackend{client: client, session: session}, nil
+}
+
+func etcdKey(name, id string) string {
+	return etcdPrefix + name + "/" + id
+}
+
+func (e *EtcdBackend) Put(ctx context.Context, svc *ServiceInstance) error {
+	buf, err := json.Marshal(svc)
+	if err != nil {
+		return err
+	}
+	_, err = e.client.Put(ctx, etcdKey(svc.Name, svc.ID), string(buf))
+	return err
+}
+
+func (e *EtcdBackend) Delete(ctx context.Context, name, id string) error {
+	_, err := e.client.Delete(ctx, etcdKey(name, id))
+	return err
+}
+
+func (e *EtcdBackend) Get(ctx context.Context, name, id string) (*ServiceInstance, bool, error) {
+	resp, err := e.client.Get(ctx, etcdKey(name, id))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, false, nil
+	}
+	svc := &ServiceInstance{}
+	if err := json.Unmarshal(resp.Kvs[0].Value, svc); err != nil {
+		return nil, false, err
+	}
+	return svc, true, nil
+}
+
+func (e *EtcdBackend) List(ctx context.Context, name string) ([]*ServiceInstance, error) {
+	resp, err := e.client.Get(ctx, etcdPrefix+name+"/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*ServiceInstance, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		svc := &ServiceInstance{}
+		if err := json.Unmarshal(kv.Value, svc); err != nil {
+			return nil, err
+		}
+		out = append(out, svc)
+	}
+	return out, nil
+}
+
+func (e *EtcdBackend) All(ctx context.Context) ([]*ServiceInstance, error) {
+	resp, err := e.client.Get(ctx, etcdPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*ServiceInstance, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		svc := &ServiceInstance{}
+		if err := json.Unmarshal(kv.Value, svc); err != nil {
+			return nil, err
+		}
+		out = append(out, svc)
+	}
+	return out, nil
+}
+
+// Cleanup only runs while holding etcdCleanupLockKey: on every replica but
+// the one that wins the lock, TryLock returns concurrency.ErrLocked
+// immediately and this is a no-op, mirroring RaftBackend.Cleanup's
+// leader-only behavior without etcd itself having a notion of "leader" for
+// this application's replicas.
+func (e *EtcdBackend) Cleanup(ctx context.Context, ttl time.Duration) (int, error) {
+	mu := concurrency.NewMutex(e.session, etcdCleanupLockKey)
+	if err := mu.TryLock(ctx); err != nil {
+		if err == concurrency.ErrLocked {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer mu.Unlock(ctx)
+
+	all, err := e.All(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-ttl)
+	removed := 0
+	for _, svc := range all {
+		if svc.LastHeartbeat.Before(cutoff) {
+			if err := e.Delete(ctx, svc.Name, svc.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (e *EtcdBackend) Close() error {
+	e.session.Close()
+	return e.client.Close()
+}
@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// fakeSnapshotSink is a minimal in-memory raft.SnapshotSink so fsm.Snapshot's
+// Persist can be exercised without a real raft.SnapshotStore.
+type fakeSnapshotSink struct {
+	bytes.Buffer
+}
+
+func (s *fakeSnapshotSink) ID() string    { return "test-snapshot" }
+func (s *fakeSnapshotSink) Cancel() error { return nil }
+func (s *fakeSnapshotSink) Close() error  { return nil }
+
+func applyCommand(t *testing.T, f *fsm, cmd command) {
+	t.Helper()
+	buf, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("marshaling command: %v", err)
+	}
+	if err, _ := f.Apply(&raft.Log{Data: buf}).(error); err != nil {
+		t.Fatalf("Apply(%+v): %v", cmd, err)
+	}
+}
+
+func TestFSMApplyPutDeleteCleanup(t *testing.T) {
+	f := newFSM()
+
+	applyCommand(t, f, command{Op: "put", Svc: &ServiceInstance{Name: "web", ID: "1", Address: "10.0.0.1", LastHeartbeat: time.Now()}})
+	applyCommand(t, f, command{Op: "put", Svc: &ServiceInstance{Name: "web", ID: "2", Address: "10.0.0.2", LastHeartbeat: time.Now().Add(-time.Hour)}})
+
+	svc, ok, err := f.get("web", "1")
+	if err != nil || !ok {
+		t.Fatalf("get after put = ok:%v, err:%v; want true, nil", ok, err)
+	}
+	if svc.Address != "10.0.0.1" {
+		t.Fatalf("get returned %+v, want address 10.0.0.1", svc)
+	}
+
+	applyCommand(t, f, command{Op: "cleanup", TTL: time.Minute})
+	if f.lastCleanupCount() != 1 {
+		t.Fatalf("lastCleanupCount() = %d, want 1", f.lastCleanupCount())
+	}
+	if _, ok, _ := f.get("web", "2"); ok {
+		t.Fatal("expected stale instance removed by cleanup")
+	}
+
+	applyCommand(t, f, command{Op: "delete", Name: "web", ID: "1"})
+	if _, ok, _ := f.get("web", "1"); ok {
+		t.Fatal("expected instance removed by delete")
+	}
+}
+
+func TestFSMSnapshotRestoreRoundTrip(t *testing.T) {
+	f := newFSM()
+	applyCommand(t, f, command{Op: "put", Svc: &ServiceInstance{Name: "web", ID: "1", Address: "10.0.0.1", LastHeartbeat: time.Now()}})
+	applyCommand(t, f, command{Op: "put", Svc: &ServiceInstance{Name: "api", ID: "1", Address: "10.0.1.1", LastHeartbeat: time.Now()}})
+
+	snap, err := f.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	sink := &fakeSnapshotSink{}
+	if err := snap.Persist(sink); err != nil {
+		t.Fatalf("Persist: %v", err)
+	}
+
+	restored := newFSM()
+	if err := restored.Restore(io.NopCloser(bytes.NewReader(sink.Bytes()))); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	all, err := restored.all()
+	if err != nil {
+		t.Fatalf("all after restore: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("all after restore returned %d instances, want 2", len(all))
+	}
+	if _, ok, err := restored.get("web", "1"); err != nil || !ok {
+		t.Fatalf("get(web, 1) after restore = ok:%v, err:%v; want true, nil", ok, err)
+	}
+	if _, ok, err := restored.get("api", "1"); err != nil || !ok {
+		t.Fatalf("get(api, 1) after restore = ok:%v, err:%v; want true, nil", ok, err)
+	}
+}
@@ -0,0 +1,71 @@
+package registry
+
+import "time"
+
+// CheckType selects how a HealthCheck is executed.
+type CheckType string
+
+// Supported check types, mirroring Consul's check model.
+const (
+	CheckHTTP CheckType = "http"
+	CheckTCP  CheckType = "tcp"
+	CheckGRPC CheckType = "grpc"
+	CheckExec CheckType = "exec"
+)
+
+// Check result states. These are stored verbatim in CheckResult.Status.
+const (
+	StatusPassing  = "passing"
+	StatusWarning  = "warning"
+	StatusCritical = "critical"
+)
+
+// HealthCheck describes one probe a HealthChecker runs periodically against
+// a registered instance. Only the fields relevant to Type need be set; the
+// rest are ignored.
+type HealthCheck struct {
+	Type CheckType `json:"type"`
+
+	// IntervalSeconds is how often the check runs. Defaults to 10s.
+	IntervalSeconds int `json:"interval_seconds,omitempty"`
+	// TimeoutSeconds bounds a single run of the check. Defaults to 5s.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+
+	// HTTP fields, used when Type == CheckHTTP.
+	HTTPURL          string `json:"http_url,omitempty"`
+	HTTPExpectStatus int    `json:"http_expect_status,omitempty"`
+	HTTPExpectBody   string `json:"http_expect_body,omitempty"` // regexp matched against the response body
+
+	// TCP fields, used when Type == CheckTCP. A successful dial is passing.
+	TCPAddress string `json:"tcp_address,omitempty"`
+
+	// gRPC fields, used when Type == CheckGRPC. Checked via the standard
+	// grpc.health.v1.Health/Check RPC.
+	GRPCAddress string `json:"grpc_address,omitempty"`
+	GRPCService string `json:"grpc_service,omitempty"`
+
+	// ExecCommand, used when Type == CheckExec, is run as-is (no shell). A
+	// zero exit code is passing; any other exit is critical.
+	ExecCommand []string `json:"exec_command,omitempty"`
+}
+
+func (c HealthCheck) interval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+func (c HealthCheck) timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// CheckResult is the most recent outcome of one HealthCheck.
+type CheckResult struct {
+	Status    string    `json:"status"`
+	Output    string    `json:"output,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregateStatus(t *testing.T) {
+	cases := []struct {
+		name    string
+		results []CheckResult
+		want    string
+	}{
+		{"no checks", nil, StatusPassing},
+		{"all passing", []CheckResult{{Status: StatusPassing}, {Status: StatusPassing}}, StatusPassing},
+		{"one warning", []CheckResult{{Status: StatusPassing}, {Status: StatusWarning}}, StatusWarning},
+		{"one critical wins", []CheckResult{{Status: StatusWarning}, {Status: StatusCritical}}, StatusCritical},
+	}
+	for _, c := range cases {
+		svc := &ServiceInstance{CheckResults: c.results}
+		if got := svc.AggregateStatus(); got != c.want {
+			t.Errorf("%s: AggregateStatus() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestHealthy(t *testing.T) {
+	passing := &ServiceInstance{CheckResults: []CheckResult{{Status: StatusPassing}}}
+	if !passing.Healthy() {
+		t.Error("instance with only passing checks should be healthy")
+	}
+
+	warning := &ServiceInstance{CheckResults: []CheckResult{{Status: StatusWarning}}}
+	if !warning.Healthy() {
+		t.Error("instance with a warning check should still be healthy")
+	}
+
+	critical := &ServiceInstance{CheckResults: []CheckResult{{Status: StatusCritical}}}
+	if critical.Healthy() {
+		t.Error("instance with a critical check should not be healthy")
+	}
+
+	noChecks := &ServiceInstance{}
+	if !noChecks.Healthy() {
+		t.Error("instance with no checks configured should be healthy")
+	}
+}
+
+func TestCheckDefaults(t *testing.T) {
+	c := HealthCheck{}
+	if got, want := c.interval(), 10*time.Second; got != want {
+		t.Errorf("interval() = %v, want %v", got, want)
+	}
+	if got, want := c.timeout(), 5*time.Second; got != want {
+		t.Errorf("timeout() = %v, want %v", got, want)
+	}
+
+	custom := HealthCheck{IntervalSeconds: 1, TimeoutSeconds: 2}
+	if got, want := custom.interval(), time.Second; got != want {
+		t.Errorf("interval() with override = %v, want %v", got, want)
+	}
+	if got, want := custom.timeout(), 2*time.Second; got != want {
+		t.Errorf("timeout() with override = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,255 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthChecker runs every registered instance's configured HealthChecks on
+// their own interval, recording outcomes via Registry.UpdateCheckResult. It
+// discovers instances (and deregistrations) by watching the registry's own
+// put/delete feed, so nothing besides a running Registry needs to register
+// with it directly.
+type HealthChecker struct {
+	reg              *Registry
+	httpClient       *http.Client
+	sem              chan struct{}
+	enableExecChecks bool
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc // "name/id" -> stops that instance's check goroutines
+}
+
+// NewHealthChecker builds a HealthChecker that runs at most maxConcurrent
+// checks at once, across every instance and check type, so a large catalog
+// of checks can't overwhelm the process or the network it's probing.
+// enableExecChecks gates CheckExec: since registration is unauthenticated,
+// running registrant-supplied commands is only safe when an operator has
+// explicitly opted in (config.Config.EnableExecChecks).
+func NewHealthChecker(reg *Registry, maxConcurrent int, enableExecChecks bool) *HealthChecker {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 16
+	}
+	return &HealthChecker{
+		reg:              reg,
+		httpClient:       &http.Client{},
+		sem:              make(chan struct{}, maxConcurrent),
+		enableExecChecks: enableExecChecks,
+		cancels:          make(map[string]context.CancelFunc),
+	}
+}
+
+// Run starts checking every currently-registered instance that declares
+// checks, then keeps up with registrations and deregistrations until ctx is
+// canceled. Callers typically run this in its own goroutine from main.
+func (hc *HealthChecker) Run(ctx context.Context) {
+	events, cancel := hc.reg.Watch(ctx, "", "", 0)
+	defer cancel()
+
+	instances, err := hc.reg.List(ctx)
+	if err == nil {
+		for _, svc := range instances {
+			hc.sync(ctx, svc)
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			svc, _ := event.Value.(*ServiceInstance)
+			if svc == nil {
+				continue
+			}
+			switch event.Type {
+			case EventPut:
+				hc.sync(ctx, svc)
+			case EventDelete:
+				hc.stop(svc.Name, svc.ID)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func instanceKey(name, id string) string { return name + "/" + id }
+
+// sync starts an instance's check goroutines the first time it's seen with
+// a non-empty Checks list. Re-registrations of an instance already being
+// watched are ignored: heartbeats and metadata updates don't change which
+// checks are running, only a deregister followed by a fresh register does.
+func (hc *HealthChecker) sync(ctx context.Context, svc *ServiceInstance) {
+	if len(svc.Checks) == 0 {
+		return
+	}
+	key := instanceKey(svc.Name, svc.ID)
+
+	hc.mu.Lock()
+	if _, running := hc.cancels[key]; running {
+		hc.mu.Unlock()
+		return
+	}
+	checkCtx, cancel := context.WithCancel(ctx)
+	hc.cancels[key] = cancel
+	hc.mu.Unlock()
+
+	for i, check := range svc.Checks {
+		go hc.runLoop(checkCtx, svc.Name, svc.ID, i, check)
+	}
+}
+
+func (hc *HealthChecker) stop(name, id string) {
+	key := instanceKey(name, id)
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if cancel, ok := hc.cancels[key]; ok {
+		cancel()
+		delete(hc.cancels, key)
+	}
+}
+
+// runLoop executes one check on its configured interval until ctx is
+// canceled. The first run is jittered within one interval so a burst of
+// registrations (e.g. a fleet restarting) doesn't probe in lockstep.
+func (hc *HealthChecker) runLoop(ctx context.Context, name, id string, index int, check HealthCheck) {
+	interval := check.interval()
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(interval))))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			hc.execute(ctx, name, id, index, check)
+			timer.Reset(interval)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (hc *HealthChecker) execute(ctx context.Context, name, id string, index int, check HealthCheck) {
+	select {
+	case hc.sem <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-hc.sem }()
+
+	runCtx, cancel := context.WithTimeout(ctx, check.timeout())
+	defer cancel()
+
+	status, output := hc.run(runCtx, check)
+
+	// ctx (not runCtx) here: the instance's deregistration context, not
+	// this single check's timeout, governs whether it's still worth
+	// recording the result.
+	_ = hc.reg.UpdateCheckResult(ctx, name, id, index, status, output)
+}
+
+func (hc *HealthChecker) run(ctx context.Context, check HealthCheck) (status, output string) {
+	switch check.Type {
+	case CheckHTTP:
+		return hc.runHTTP(ctx, check)
+	case CheckTCP:
+		return hc.runTCP(ctx, check)
+	case CheckGRPC:
+		return hc.runGRPC(ctx, check)
+	case CheckExec:
+		if !hc.enableExecChecks {
+			return StatusCritical, "exec checks are disabled (set MIRAGE_ENABLE_EXEC_CHECKS=true to allow them)"
+		}
+		return hc.runExec(ctx, check)
+	default:
+		return StatusCritical, fmt.Sprintf("health: unknown check type %q", check.Type)
+	}
+}
+
+func (hc *HealthChecker) runHTTP(ctx context.Context, check HealthCheck) (string, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.HTTPURL, nil)
+	if err != nil {
+		return StatusCritical, err.Error()
+	}
+	resp, err := hc.httpClient.Do(req)
+	if err != nil {
+		return StatusCritical, err.Error()
+	}
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	_, _ = body.ReadFrom(resp.Body)
+
+	if check.HTTPExpectStatus != 0 && resp.StatusCode != check.HTTPExpectStatus {
+		return StatusCritical, fmt.Sprintf("status %d, expected %d", resp.StatusCode, check.HTTPExpectStatus)
+	}
+	if check.HTTPExpectBody != "" {
+		re, err := regexp.Compile(check.HTTPExpectBody)
+		if err != nil {
+			return StatusCritical, fmt.Sprintf("invalid body regex: %v", err)
+		}
+		if !re.Match(body.Bytes()) {
+			return StatusCritical, "response body did not match expected pattern"
+		}
+	}
+	switch {
+	case resp.StatusCode >= 500:
+		return StatusCritical, fmt.Sprintf("status %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return StatusWarning, fmt.Sprintf("status %d", resp.StatusCode)
+	default:
+		return StatusPassing, fmt.Sprintf("status %d", resp.StatusCode)
+	}
+}
+
+func (hc *HealthChecker) runTCP(ctx context.Context, check HealthCheck) (string, string) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", check.TCPAddress)
+	if err != nil {
+		return StatusCritical, err.Error()
+	}
+	_ = conn.Close()
+	return StatusPassing, "connected"
+}
+
+func (hc *HealthChecker) runGRPC(ctx context.Context, check HealthCheck) (string, string) {
+	conn, err := grpc.Dial(check.GRPCAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return StatusCritical, err.Error()
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: check.GRPCService})
+	if err != nil {
+		return StatusCritical, err.Error()
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return StatusCritical, resp.Status.String()
+	}
+	return StatusPassing, resp.Status.String()
+}
+
+func (hc *HealthChecker) runExec(ctx context.Context, check HealthCheck) (string, string) {
+	if len(check.ExecCommand) == 0 {
+		return StatusCritical, "exec check has no command configured"
+	}
+	cmd := exec.CommandContext(ctx, check.ExecCommand[0], check.ExecCommand[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return StatusCritical, fmt.Sprintf("%s: %v", string(output), err)
+	}
+	return StatusPassing, string(output)
+}
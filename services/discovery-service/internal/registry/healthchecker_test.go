@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestRunExecDisabledByDefault(t *testing.T) {
+	hc := NewHealthChecker(NewRegistry(NewMemoryBackend(), 0), 1, false)
+
+	status, output := hc.run(context.Background(), HealthCheck{Type: CheckExec, ExecCommand: []string{"true"}})
+	if status != StatusCritical {
+		t.Fatalf("status = %q, want %q (exec checks must be refused when disabled)", status, StatusCritical)
+	}
+	if output == "" {
+		t.Fatal("expected a non-empty explanation for the refused exec check")
+	}
+}
+
+func TestRunExecAllowedWhenEnabled(t *testing.T) {
+	hc := NewHealthChecker(NewRegistry(NewMemoryBackend(), 0), 1, true)
+
+	status, _ := hc.run(context.Background(), HealthCheck{Type: CheckExec, ExecCommand: []string{"true"}})
+	if status != StatusPassing {
+		t.Fatalf("status = %q, want %q", status, StatusPassing)
+	}
+
+	status, _ = hc.run(context.Background(), HealthCheck{Type: CheckExec, ExecCommand: []string{"false"}})
+	if status != StatusCritical {
+		t.Fatalf("status = %q, want %q for a nonzero exit", status, StatusCritical)
+	}
+}
+
+func TestRunTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	hc := NewHealthChecker(NewRegistry(NewMemoryBackend(), 0), 1, false)
+	status, _ := hc.run(context.Background(), HealthCheck{Type: CheckTCP, TCPAddress: ln.Addr().String()})
+	if status != StatusPassing {
+		t.Fatalf("status = %q, want %q", status, StatusPassing)
+	}
+
+	if err := ln.Close(); err != nil {
+		t.Fatalf("close listener: %v", err)
+	}
+	status, _ = hc.run(context.Background(), HealthCheck{Type: CheckTCP, TCPAddress: ln.Addr().String()})
+	if status != StatusCritical {
+		t.Fatalf("status against a closed listener = %q, want %q", status, StatusCritical)
+	}
+}
+
+func TestRunUnknownCheckType(t *testing.T) {
+	hc := NewHealthChecker(NewRegistry(NewMemoryBackend(), 0), 1, false)
+	status, _ := hc.run(context.Background(), HealthCheck{Type: "bogus"})
+	if status != StatusCritical {
+		t.Fatalf("status = %q, want %q", status, StatusCritical)
+	}
+}
@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps instances in a process-local map. It satisfies Backend
+// but has no persistence and no replication: a restart or crash loses every
+// registration, and it cannot be shared across discovery-service replicas.
+// It remains the default for local development.
+type MemoryBackend struct {
+	mu   sync.RWMutex
+	data map[string]map[string]*ServiceInstance // name -> id -> instance
+}
+
+// NewMemoryBackend constructs an empty MemoryBackend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{data: make(map[string]map[string]*ServiceInstance)}
+}
+
+func (m *MemoryBackend) Put(_ context.Context, svc *ServiceInstance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	instances, ok := m.data[svc.Name]
+	if !ok {
+		instances = make(map[string]*ServiceInstance)
+		m.data[svc.Name] = instances
+	}
+	cp := *svc
+	instances[svc.ID] = &cp
+	return nil
+}
+
+func (m *MemoryBackend) Delete(_ context.Context, name, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if instances, ok := m.data[name]; ok {
+		delete(instances, id)
+		if len(instances) == 0 {
+			delete(m.data, name)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryBackend) Get(_ context.Context, name, id string) (*ServiceInstance, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	instances, ok := m.data[name]
+	if !ok {
+		return nil, false, nil
+	}
+	svc, ok := instances[id]
+	if !ok {
+		return nil, false, nil
+	}
+	cp := *svc
+	return &cp, true, nil
+}
+
+func (m *MemoryBackend) List(_ context.Context, name string) ([]*ServiceInstance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	instances := m.data[name]
+	out := make([]*ServiceInstance, 0, len(instances))
+	for _, svc := range instances {
+		cp := *svc
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func (m *MemoryBackend) All(_ context.Context) ([]*ServiceInstance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []*ServiceInstance
+	for _, instances := range m.data {
+		for _, svc := range instances {
+			cp := *svc
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryBackend) Cleanup(_ context.Context, ttl time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := time.Now().Add(-ttl)
+	removed := 0
+	for name, instances := range m.data {
+		for id, svc := range instances {
+			if svc.LastHeartbeat.Before(cutoff) {
+				delete(instances, id)
+				removed++
+			}
+		}
+		if len(instances) == 0 {
+			delete(m.data, name)
+		}
+	}
+	return removed, nil
+}
+
+func (m *MemoryBackend) Close() error { return nil }
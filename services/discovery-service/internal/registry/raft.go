@@ -0,0 +1,275 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+)
+
+// RaftConfig describes the parameters needed to stand up a Raft-replicated
+// RaftBackend. NodeID and BindAddr identify this node within the cluster;
+// Bootstrap is set on exactly one node the first time a cluster is formed;
+// Peers lists the other known voters' raft addresses, which the bootstrapping
+// node puts into the initial configuration (each address doubling as that
+// voter's server ID).
+type RaftConfig struct {
+	NodeID    string
+	BindAddr  string
+	DataDir   string
+	Bootstrap bool
+	Peers     []string
+}
+
+// command is the payload applied through the raft log. Every mutation to
+// the registry (Put/Delete/Cleanup) is replicated as one of these so every
+// voter ends up with an identical fsm state.
+type command struct {
+	Op   string           `json:"op"` // "put", "delete", or "cleanup"
+	Svc  *ServiceInstance `json:"svc,omitempty"`
+	Name string           `json:"name,omitempty"`
+	ID   string           `json:"id,omitempty"`
+	TTL  time.Duration    `json:"ttl,omitempty"`
+}
+
+// RaftBackend replicates the service catalog across a quorum of
+// discovery-service instances using hashicorp/raft. Writes are only
+// accepted on the leader; reads are served from the local fsm, which lags
+// the leader by at most one round of replication. This is the backend to
+// choose for self-hosted multi-replica HA without depending on an external
+// coordination service such as etcd or Consul.
+type RaftBackend struct {
+	raft *raft.Raft
+	fsm  *fsm
+}
+
+// NewRaftBackend starts (or rejoins) a raft node per cfg and returns a
+// Backend backed by it. The /cluster API (see internal/api) only reports
+// peers, leader and log index; voters outside the bootstrap configuration
+// are added by calling AddVoter on the leader.
+func NewRaftBackend(cfg RaftConfig) (*RaftBackend, error) {
+	if err := os.MkdirAll(cfg.DataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("registry: creating raft data dir: %w", err)
+	}
+
+	fsm := newFSM()
+
+	raftCfg := raft.DefaultConfig()
+	raftCfg.LocalID = raft.ServerID(cfg.NodeID)
+
+	addr, err := net.ResolveTCPAddr("tcp", cfg.BindAddr)
+	if err != nil {
+		return nil, fmt.Errorf("registry: resolving raft bind addr: %w", err)
+	}
+	transport, err := raft.NewTCPTransport(cfg.BindAddr, addr, 3, 10*time.Second, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("registry: creating raft transport: %w", err)
+	}
+
+	snapshots, err := raft.NewFileSnapshotStore(cfg.DataDir, 2, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("registry: creating raft snapshot store: %w", err)
+	}
+
+	logStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.DataDir, "raft-log.bolt"))
+	if err != nil {
+		return nil, fmt.Errorf("registry: creating raft log store: %w", err)
+	}
+	stableStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.DataDir, "raft-stable.bolt"))
+	if err != nil {
+		return nil, fmt.Errorf("registry: creating raft stable store: %w", err)
+	}
+
+	r, err := raft.NewRaft(raftCfg, fsm, logStore, stableStore, snapshots, transport)
+	if err != nil {
+		return nil, fmt.Errorf("registry: starting raft: %w", err)
+	}
+
+	if cfg.Bootstrap {
+		servers := []raft.Server{{ID: raftCfg.LocalID, Address: transport.LocalAddr()}}
+		for _, peer := range cfg.Peers {
+			servers = append(servers, raft.Server{ID: raft.ServerID(peer), Address: raft.ServerAddress(peer)})
+		}
+		r.BootstrapCluster(raft.Configuration{Servers: servers})
+	}
+
+	return &RaftBackend{raft: r, fsm: fsm}, nil
+}
+
+func (b *RaftBackend) apply(cmd command) error {
+	if b.raft.State() != raft.Leader {
+		return fmt.Errorf("registry: not leader, write must go to %s", b.raft.Leader())
+	}
+	buf, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	future := b.raft.Apply(buf, 10*time.Second)
+	return future.Error()
+}
+
+func (b *RaftBackend) Put(_ context.Context, svc *ServiceInstance) error {
+	return b.apply(command{Op: "put", Svc: svc})
+}
+
+func (b *RaftBackend) Delete(_ context.Context, name, id string) error {
+	return b.apply(command{Op: "delete", Name: name, ID: id})
+}
+
+func (b *RaftBackend) Get(_ context.Context, name, id string) (*ServiceInstance, bool, error) {
+	return b.fsm.get(name, id)
+}
+
+func (b *RaftBackend) List(_ context.Context, name string) ([]*ServiceInstance, error) {
+	return b.fsm.list(name)
+}
+
+func (b *RaftBackend) All(_ context.Context) ([]*ServiceInstance, error) {
+	return b.fsm.all()
+}
+
+// Cleanup is a no-op on followers: only the leader replicates a cleanup
+// command, which avoids every replica independently deleting (and
+// re-electing a winner for) the same expired entries.
+func (b *RaftBackend) Cleanup(_ context.Context, ttl time.Duration) (int, error) {
+	if b.raft.State() != raft.Leader {
+		return 0, nil
+	}
+	if err := b.apply(command{Op: "cleanup", TTL: ttl}); err != nil {
+		return 0, err
+	}
+	return b.fsm.lastCleanupCount(), nil
+}
+
+func (b *RaftBackend) Close() error {
+	return b.raft.Shutdown().Error()
+}
+
+// ClusterInfo implementation, consumed by the /cluster API.
+
+func (b *RaftBackend) Leader() string {
+	return string(b.raft.Leader())
+}
+
+func (b *RaftBackend) Peers() []string {
+	future := b.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return nil
+	}
+	peers := make([]string, 0)
+	for _, server := range future.Configuration().Servers {
+		peers = append(peers, string(server.ID))
+	}
+	return peers
+}
+
+func (b *RaftBackend) LastIndex() uint64 {
+	return b.raft.LastIndex()
+}
+
+func (b *RaftBackend) IsLeader() bool {
+	return b.raft.State() == raft.Leader
+}
+
+// AddVoter adds a new voting member to the cluster. Must be called against
+// the current leader.
+func (b *RaftBackend) AddVoter(id, address string) error {
+	if b.raft.State() != raft.Leader {
+		return fmt.Errorf("registry: not leader, add voter against %s instead", b.raft.Leader())
+	}
+	return b.raft.AddVoter(raft.ServerID(id), raft.ServerAddress(address), 0, 10*time.Second).Error()
+}
+
+// fsm is the raft finite-state-machine applying replicated commands onto an
+// in-memory catalog. It intentionally reuses MemoryBackend's map layout
+// rather than duplicating it.
+type fsm struct {
+	store        *MemoryBackend
+	cleanupCount int
+}
+
+func newFSM() *fsm {
+	return &fsm{store: NewMemoryBackend()}
+}
+
+func (f *fsm) Apply(log *raft.Log) interface{} {
+	var cmd command
+	if err := json.Unmarshal(log.Data, &cmd); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	switch cmd.Op {
+	case "put":
+		return f.store.Put(ctx, cmd.Svc)
+	case "delete":
+		return f.store.Delete(ctx, cmd.Name, cmd.ID)
+	case "cleanup":
+		removed, err := f.store.Cleanup(ctx, cmd.TTL)
+		f.cleanupCount = removed
+		return err
+	default:
+		return fmt.Errorf("registry: unknown raft command %q", cmd.Op)
+	}
+}
+
+func (f *fsm) get(name, id string) (*ServiceInstance, bool, error) {
+	return f.store.Get(context.Background(), name, id)
+}
+
+func (f *fsm) list(name string) ([]*ServiceInstance, error) {
+	return f.store.List(context.Background(), name)
+}
+
+func (f *fsm) all() ([]*ServiceInstance, error) {
+	return f.store.All(context.Background())
+}
+
+func (f *fsm) lastCleanupCount() int {
+	return f.cleanupCount
+}
+
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	all, err := f.store.All(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &fsmSnapshot{instances: all}, nil
+}
+
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+	var instances []*ServiceInstance
+	if err := json.NewDecoder(rc).Decode(&instances); err != nil {
+		return err
+	}
+	f.store = NewMemoryBackend()
+	ctx := context.Background()
+	for _, svc := range instances {
+		if err := f.store.Put(ctx, svc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fsmSnapshot struct {
+	instances []*ServiceInstance
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	err := json.NewEncoder(sink).Encode(s.instances)
+	if err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
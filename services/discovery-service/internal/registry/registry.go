@@ -0,0 +1,308 @@
+// Package registry implements the discovery-service's service catalog: the
+// set of registered instances, their health, and the heartbeat/TTL rules
+// used to expire stale entries. Storage and replication are delegated to a
+// pluggable Backend so the same Registry API works whether a single process
+// is keeping everything in memory or a quorum of replicas is coordinating
+// through Raft.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/van1sh/Mirage/pkg/pubsub"
+	"github.com/van1sh/Mirage/pkg/serverkit"
+)
+
+// tracer instruments the registry's backend operations so they show up as
+// child spans of the HTTP/gRPC span that triggered them.
+var tracer = serverkit.Tracer("discovery-service/registry")
+
+// watchBacklog bounds how many historical registration/deregistration
+// events are retained so a watcher can resume after a brief disconnect
+// instead of re-listing the whole catalog.
+const watchBacklog = 256
+
+// Event types published on the registry's watch feed.
+const (
+	EventPut    = "put"
+	EventDelete = "delete"
+	// EventHealth is published whenever an instance's AggregateStatus
+	// changes as a result of UpdateCheckResult, separately from EventPut so
+	// /services/:name/health can stream just health transitions.
+	EventHealth = "health"
+)
+
+// ServiceInstance is a single registered endpoint for a named service.
+type ServiceInstance struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Address       string            `json:"address"`
+	Port          int               `json:"port"`
+	Tags          []string          `json:"tags,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	RegisteredAt  time.Time         `json:"registered_at"`
+	LastHeartbeat time.Time         `json:"last_heartbeat"`
+
+	// Checks are the active health checks a HealthChecker runs against this
+	// instance. Empty means the instance relies solely on heartbeat-based
+	// liveness (the original behavior).
+	Checks []HealthCheck `json:"checks,omitempty"`
+
+	// CheckResults holds the most recent outcome of each entry in Checks,
+	// indexed the same way. It is populated by HealthChecker via
+	// Registry.UpdateCheckResult, never by clients.
+	CheckResults []CheckResult `json:"check_results,omitempty"`
+}
+
+// AggregateStatus rolls CheckResults up into a single status: critical if
+// any check is critical, else warning if any check is warning, else
+// passing. An instance with no checks configured is always passing, since
+// it isn't opted into active health checking.
+func (svc *ServiceInstance) AggregateStatus() string {
+	status := StatusPassing
+	for _, result := range svc.CheckResults {
+		switch result.Status {
+		case StatusCritical:
+			return StatusCritical
+		case StatusWarning:
+			status = StatusWarning
+		}
+	}
+	return status
+}
+
+// Healthy reports whether svc should be returned by a lookup filtered with
+// ?healthy=true. Following Consul's convention, critical excludes an
+// instance from that filter; warning does not.
+func (svc *ServiceInstance) Healthy() bool {
+	return svc.AggregateStatus() != StatusCritical
+}
+
+// Registry is the public API used by the rest of discovery-service. It
+// validates input and enforces the heartbeat TTL, delegating all reads,
+// writes and replication concerns to a Backend.
+type Registry struct {
+	backend          Backend
+	heartbeatTimeout time.Duration
+	topic            *pubsub.Topic
+}
+
+// NewRegistry builds a Registry on top of an arbitrary Backend. Use this when
+// the backend is selected dynamically via config.Load, e.g. bolt/badger for a
+// single persistent node, etcd/consul for external coordination, or raft for
+// a self-hosted HA quorum.
+func NewRegistry(backend Backend, heartbeatTimeout time.Duration) *Registry {
+	return &Registry{backend: backend, heartbeatTimeout: heartbeatTimeout, topic: pubsub.NewTopic(watchBacklog)}
+}
+
+// NewInMemoryRegistry keeps the original zero-configuration behavior: all
+// registrations live only in process memory and are lost on restart. Kept
+// for local development and tests; production deployments should select a
+// persistent backend via config.Load instead.
+func NewInMemoryRegistry(heartbeatTimeout time.Duration) *Registry {
+	return NewRegistry(NewMemoryBackend(), heartbeatTimeout)
+}
+
+// Register creates or refreshes a service instance.
+func (r *Registry) Register(ctx context.Context, svc *ServiceInstance) error {
+	ctx, span := tracer.Start(ctx, "registry.Register")
+	defer span.End()
+	span.SetAttributes(attribute.String("service.name", svc.Name), attribute.String("service.id", svc.ID))
+
+	if svc.Name == "" || svc.ID == "" {
+		return fmt.Errorf("registry: name and id are required")
+	}
+	now := time.Now()
+	if svc.RegisteredAt.IsZero() {
+		svc.RegisteredAt = now
+	}
+	svc.LastHeartbeat = now
+	if err := r.backend.Put(ctx, svc); err != nil {
+		return err
+	}
+	r.topic.Publish(EventPut, svc.Name, svc)
+	return nil
+}
+
+// Heartbeat refreshes the LastHeartbeat of an existing instance. It does
+// not publish a watch event: heartbeats are far more frequent than actual
+// registration changes and watchers only care about the latter (health
+// transitions are published separately as EventHealth by UpdateCheckResult).
+func (r *Registry) Heartbeat(ctx context.Context, name, id string) error {
+	ctx, span := tracer.Start(ctx, "registry.Heartbeat")
+	defer span.End()
+	span.SetAttributes(attribute.String("service.name", name), attribute.String("service.id", id))
+
+	svc, ok, err := r.backend.Get(ctx, name, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("registry: instance %s/%s not found", name, id)
+	}
+	svc.LastHeartbeat = time.Now()
+	return r.backend.Put(ctx, svc)
+}
+
+// Deregister removes a single instance.
+func (r *Registry) Deregister(ctx context.Context, name, id string) error {
+	ctx, span := tracer.Start(ctx, "registry.Deregister")
+	defer span.End()
+	span.SetAttributes(attribute.String("service.name", name), attribute.String("service.id", id))
+
+	if err := r.backend.Delete(ctx, name, id); err != nil {
+		return err
+	}
+	r.topic.Publish(EventDelete, name, &ServiceInstance{Name: name, ID: id})
+	return nil
+}
+
+// UpdateCheckResult records the outcome of a single health check (by its
+// index into the instance's Checks) and, if the instance's AggregateStatus
+// changed as a result, publishes an EventHealth so /services/:name/health
+// watchers see the transition. Called by HealthChecker, not by API clients.
+func (r *Registry) UpdateCheckResult(ctx context.Context, name, id string, checkIndex int, status, output string) error {
+	ctx, span := tracer.Start(ctx, "registry.UpdateCheckResult")
+	defer span.End()
+	span.SetAttributes(attribute.String("service.name", name), attribute.String("service.id", id))
+
+	svc, ok, err := r.backend.Get(ctx, name, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("registry: instance %s/%s not found", name, id)
+	}
+
+	prevStatus := svc.AggregateStatus()
+	for len(svc.CheckResults) <= checkIndex {
+		svc.CheckResults = append(svc.CheckResults, CheckResult{})
+	}
+	svc.CheckResults[checkIndex] = CheckResult{Status: status, Output: output, UpdatedAt: time.Now()}
+
+	if err := r.backend.Put(ctx, svc); err != nil {
+		return err
+	}
+	if svc.AggregateStatus() != prevStatus {
+		r.topic.Publish(EventHealth, svc.Name, svc)
+	}
+	return nil
+}
+
+// WatchHealth streams EventHealth transitions, optionally filtered to a
+// single service name (empty streams every service's transitions). The
+// returned cancel func must be called once the caller stops watching.
+func (r *Registry) WatchHealth(ctx context.Context, name string, fromRevision uint64) (<-chan pubsub.Event, func()) {
+	events, cancel := r.topic.Subscribe(ctx, fromRevision)
+
+	filtered := make(chan pubsub.Event, 64)
+	go func() {
+		defer close(filtered)
+		for event := range events {
+			if event.Type != EventHealth {
+				continue
+			}
+			if name != "" && event.Key != name {
+				continue
+			}
+			select {
+			case filtered <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return filtered, cancel
+}
+
+// Watch streams registration, deregistration and health-change events
+// (EventPut, EventDelete, EventHealth), optionally filtered by service name
+// and/or tag, starting after fromRevision (0 for "from now").
+// The returned cancel func must be called once the caller stops watching.
+func (r *Registry) Watch(ctx context.Context, name, tag string, fromRevision uint64) (<-chan pubsub.Event, func()) {
+	if fromRevision == 0 {
+		fromRevision = r.topic.Revision()
+	}
+	events, cancel := r.topic.Subscribe(ctx, fromRevision)
+	if name == "" && tag == "" {
+		return events, cancel
+	}
+
+	filtered := make(chan pubsub.Event, 64)
+	go func() {
+		defer close(filtered)
+		for event := range events {
+			svc, _ := event.Value.(*ServiceInstance)
+			if name != "" && event.Key != name {
+				continue
+			}
+			if tag != "" && (svc == nil || !hasTag(svc.Tags, tag)) {
+				continue
+			}
+			select {
+			case filtered <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return filtered, cancel
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the live instances registered under name.
+func (r *Registry) Lookup(ctx context.Context, name string) ([]*ServiceInstance, error) {
+	ctx, span := tracer.Start(ctx, "registry.Lookup")
+	defer span.End()
+	span.SetAttributes(attribute.String("service.name", name))
+	return r.backend.List(ctx, name)
+}
+
+// List returns every registered instance across all service names.
+func (r *Registry) List(ctx context.Context) ([]*ServiceInstance, error) {
+	ctx, span := tracer.Start(ctx, "registry.List")
+	defer span.End()
+	return r.backend.All(ctx)
+}
+
+// CleanupStaleServices removes instances whose heartbeat has exceeded the
+// configured TTL. Backends that replicate state only act on this while they
+// hold leadership (raft) or a cleanup lock (etcd, consul), so running the
+// ticker on every replica is safe and does not create duplicate deletes.
+func (r *Registry) CleanupStaleServices() (int, error) {
+	return r.backend.Cleanup(context.Background(), r.heartbeatTimeout)
+}
+
+// Close releases any resources (file handles, client connections, the raft
+// transport) held by the underlying backend.
+func (r *Registry) Close() error {
+	return r.backend.Close()
+}
+
+// ClusterInfo is implemented by backends that run as a replicated cluster
+// (currently only the raft backend). The /cluster API type-asserts on this
+// to expose peers, leadership and replication progress for observability.
+type ClusterInfo interface {
+	Leader() string
+	Peers() []string
+	LastIndex() uint64
+	IsLeader() bool
+}
+
+// Cluster returns the backend's ClusterInfo, if it implements one.
+func (r *Registry) Cluster() (ClusterInfo, bool) {
+	ci, ok := r.backend.(ClusterInfo)
+	return ci, ok
+}